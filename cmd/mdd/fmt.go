@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/shibukawa/formatdata-go"
+)
+
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	write := fs.Bool("w", false, "write result to source file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		io.WriteString(stdout, formatTables(string(src)))
+		return 0
+	}
+	for _, filename := range fs.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		result := formatTables(string(src))
+		if !*write {
+			io.WriteString(stdout, result)
+			continue
+		}
+		if result == string(src) {
+			continue
+		}
+		st, err := os.Stat(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if err := os.WriteFile(filename, []byte(result), st.Mode().Perm()); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+var (
+	delimiterRow = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	fenceStart   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// formatTables normalizes markdown tables in src. Contents in code fences are kept.
+func formatTables(src string) string {
+	lines := strings.SplitAfter(src, "\n")
+	var b strings.Builder
+	var fence string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimRight(line, "\r\n")
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(trimmed), fence) {
+				fence = ""
+			}
+			b.WriteString(line)
+			continue
+		}
+		if m := fenceStart.FindStringSubmatch(trimmed); m != nil {
+			fence = m[1]
+			b.WriteString(line)
+			continue
+		}
+		if strings.Contains(trimmed, "|") && i+1 < len(lines) && delimiterRow.MatchString(strings.TrimRight(lines[i+1], "\r\n")) && !strings.HasPrefix(trimmed, "    ") {
+			end := i + 2
+			for end < len(lines) {
+				l := strings.TrimRight(lines[end], "\r\n")
+				if strings.TrimSpace(l) == "" || !strings.Contains(l, "|") {
+					break
+				}
+				end++
+			}
+			if formatted, ok := formatTable(lines[i:end]); ok {
+				b.WriteString(formatted)
+				i = end - 1
+				continue
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func formatTable(lines []string) (string, bool) {
+	newLine := "\n"
+	if strings.HasSuffix(lines[0], "\r\n") {
+		newLine = "\r\n"
+	}
+	header := strings.TrimRight(lines[0], "\r\n")
+	indent := header[:len(header)-len(strings.TrimLeft(header, " "))]
+
+	headers := splitTableRow(header)
+	aligns := splitTableRow(strings.TrimRight(lines[1], "\r\n"))
+	if len(lines) < 3 || len(aligns) != len(headers) {
+		return "", false
+	}
+	cells := make([][]any, 0, len(lines)-1)
+	row := make([]any, len(headers))
+	for i, h := range headers {
+		row[i] = h
+	}
+	cells = append(cells, row)
+	for _, l := range lines[2:] {
+		values := splitTableRow(strings.TrimRight(l, "\r\n"))
+		row := make([]any, len(headers))
+		for i := range row {
+			if i < len(values) {
+				row[i] = values[i]
+			} else {
+				row[i] = ""
+			}
+		}
+		cells = append(cells, row)
+	}
+	var buffer bytes.Buffer
+	err := formatdata.FormatDataWithoutColor(cells, &buffer, formatdata.Opt{
+		OutputFormat: formatdata.Markdown,
+	})
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	for i, l := range strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n") {
+		if i == 1 {
+			l = applyAlignment(l, aligns)
+		}
+		b.WriteString(indent)
+		b.WriteString(l)
+		b.WriteString(newLine)
+	}
+	return b.String(), true
+}
+
+// splitTableRow splits row by '|'. Escaped pipes and pipes in code spans are kept.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var result []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			cell.WriteByte(c)
+			cell.WriteByte(line[i+1])
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			result = append(result, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(result, strings.TrimSpace(cell.String()))
+}
+
+// applyAlignment restores alignment markers (:--, --:, :-:) into delimiter row.
+func applyAlignment(delimiter string, aligns []string) string {
+	columns := strings.Split(strings.Trim(delimiter, "|"), "|")
+	for i, c := range columns {
+		if i >= len(aligns) {
+			break
+		}
+		a := aligns[i]
+		dashes := []byte(c)
+		if strings.HasPrefix(a, ":") {
+			dashes[0] = ':'
+		}
+		if strings.HasSuffix(a, ":") {
+			dashes[len(dashes)-1] = ':'
+		}
+		columns[i] = string(dashes)
+	}
+	return "|" + strings.Join(columns, "|") + "|"
+}
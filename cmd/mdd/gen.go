@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shibukawa/mdd-go"
+)
+
+func runGen(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("s", "", "jig schema file (YAML/JSON)")
+	pkg := fs.String("p", os.Getenv("GOPACKAGE"), "package name (default is $GOPACKAGE set by go generate)")
+	typeName := fs.String("t", "Doc", "root document type name")
+	jigName := fs.String("j", "", "variable name of DocJig (default is type name + \"Jig\")")
+	tags := fs.Bool("tags", false, "use struct tags instead of builder methods")
+	output := fs.String("o", "", "output file (default is stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaPath == "" {
+		fmt.Fprintln(stderr, "schema file is required (-s)")
+		return 2
+	}
+	schema, err := mdd.LoadSchemaFile(*schemaPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	var buffer bytes.Buffer
+	err = schema.GenerateGo(&buffer, mdd.GenerateGoOption{
+		Package:  *pkg,
+		TypeName: *typeName,
+		JigName:  *jigName,
+		Tags:     *tags,
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if *output == "" {
+		stdout.Write(buffer.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*output, buffer.Bytes(), 0644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shibukawa/mdd-go"
+)
+
+func runInfer(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("infer", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("f", "yaml", "output format (yaml, json, go)")
+	pkg := fs.String("p", os.Getenv("GOPACKAGE"), "package name for go format")
+	typeName := fs.String("t", "Doc", "root document type name for go format")
+	tags := fs.Bool("tags", false, "use struct tags instead of builder methods for go format")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "sample markdown files are required")
+		return 2
+	}
+	var samples []mdd.InferSample
+	for _, filename := range fs.Args() {
+		c, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		samples = append(samples, mdd.InferSample{Name: filename, Source: string(c)})
+	}
+	schema, warnings := mdd.InferSchema(samples...)
+	for _, w := range warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	var err error
+	switch *format {
+	case "yaml":
+		err = schema.WriteYAML(stdout)
+	case "json":
+		err = schema.WriteJSON(stdout)
+	case "go":
+		if *pkg == "" {
+			*pkg = "main"
+		}
+		err = schema.GenerateGo(stdout, mdd.GenerateGoOption{
+			Package:  *pkg,
+			TypeName: *typeName,
+			Tags:     *tags,
+		})
+	default:
+		fmt.Fprintf(stderr, "unknown format: %s\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("s", "", "jig schema file (YAML/JSON)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "file patterns are required")
+		return 2
+	}
+	jig, err := loadJig(*schemaPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	var failed int
+	var count int
+	for _, pattern := range fs.Args() {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		if len(files) == 0 {
+			fmt.Fprintf(stderr, "pattern matches no files: %#q\n", pattern)
+			failed++
+			continue
+		}
+		for _, filename := range files {
+			if st, err := os.Stat(filename); err != nil || st.IsDir() {
+				continue
+			}
+			count++
+			if _, err := jig.ParseFile(filename); err != nil {
+				fmt.Fprintf(stdout, "%s: %v\n", filepath.ToSlash(filename), err)
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d error(s) in %d file(s)\n", failed, count)
+		return 1
+	}
+	return 0
+}
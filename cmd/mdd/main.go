@@ -0,0 +1,70 @@
+// Command mdd parses, validates and formats markdown documents by using declarative jig schema.
+//
+//	mdd parse -s schema.yaml [-f json|yaml] [files...]
+//	mdd template -s schema.yaml [--lang ja]
+//	mdd lint -s schema.yaml patterns...
+//	mdd fmt [-w] [files...]
+//	mdd gen -s schema.yaml [-p package] [-t TypeName] [-tags] [-o output.go]
+//	mdd infer [-f yaml|json|go] samples...
+//	mdd tangle [-o dir] [-line] [-n] patterns...
+//
+// If files are not specified, parse and fmt read stdin.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"parse", "parse markdown and dump as JSON/YAML", runParse},
+		{"template", "generate markdown template", runTemplate},
+		{"lint", "validate markdown files", runLint},
+		{"fmt", "normalize markdown tables", runFmt},
+		{"gen", "generate Go types and jig definition from schema", runGen},
+		{"infer", "infer schema from sample markdown files", runInfer},
+		{"tangle", "extract source files from code fences", runTangle},
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: mdd <command> [options] [args]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return 0
+	}
+	fmt.Fprintf(stderr, "unknown command: %s\n\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func execute(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var o, e bytes.Buffer
+	code = run(args, strings.NewReader(stdin), &o, &e)
+	return code, o.String(), e.String()
+}
+
+func TestParse(t *testing.T) {
+	src, err := os.ReadFile("testdata/ok.md")
+	assert.NoError(t, err)
+
+	code, stdout, stderr := execute(t, string(src), "parse", "-s", "testdata/sql.yaml")
+	assert.Equal(t, 0, code, stderr)
+	assert.JSONEq(t, `{
+		"Name": "Query User",
+		"SQL": "select * from users;",
+		"CRUDMatrix": [{"Table": "users", "R": true}]
+	}`, stdout)
+
+	code, stdout, stderr = execute(t, "", "parse", "-s", "testdata/sql.yaml", "-f", "yaml", "testdata/ok.md")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Name: Query User\n")
+
+	code, _, stderr = execute(t, "", "parse", "-s", "testdata/sql.yaml", "testdata/ng.md")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "testdata/ng.md: required column(Table) are missing (inside '' section)\n", stderr)
+}
+
+func TestTemplate(t *testing.T) {
+	code, stdout, stderr := execute(t, "", "template", "-s", "testdata/sql.yaml", "--lang", "ja")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "## CRUDマトリックス\n")
+	assert.Contains(t, stdout, "select * from users;\n")
+}
+
+func TestLint(t *testing.T) {
+	code, stdout, _ := execute(t, "", "lint", "-s", "testdata/sql.yaml", "testdata/ok.md")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "", stdout)
+
+	code, stdout, stderr := execute(t, "", "lint", "-s", "testdata/sql.yaml", "testdata/*.md")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "testdata/ng.md: required column(Table) are missing (inside '' section)\n", stdout)
+	assert.Equal(t, "1 error(s) in 2 file(s)\n", stderr)
+}
+
+func TestFmt(t *testing.T) {
+	src := strings.Join([]string{
+		"# Title",
+		"",
+		"| Name | Value |",
+		"|:--|--:|",
+		"| a | 1 |",
+		"| long name | `a|b` |",
+		"",
+		"```md",
+		"| a | b |",
+		"|-|-|",
+		"```",
+		"",
+	}, "\n")
+	want := strings.Join([]string{
+		"# Title",
+		"",
+		"| Name      | Value |",
+		"|:----------|------:|",
+		"| a         | 1     |",
+		"| long name | `a|b` |",
+		"",
+		"```md",
+		"| a | b |",
+		"|-|-|",
+		"```",
+		"",
+	}, "\n")
+	code, stdout, stderr := execute(t, src, "fmt")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, want, stdout)
+
+	filename := filepath.Join(t.TempDir(), "doc.md")
+	assert.NoError(t, os.WriteFile(filename, []byte(src), 0644))
+	code, stdout, _ = execute(t, "", "fmt", "-w", filename)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "", stdout)
+	result, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, want, string(result))
+}
+
+func TestGen(t *testing.T) {
+	code, stdout, stderr := execute(t, "", "gen", "-s", "testdata/sql.yaml", "-p", "sample", "-t", "SQLDoc")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "package sample\n")
+	assert.Contains(t, stdout, "var SQLDocJig = mdd.NewDocJig[SQLDoc]()\n")
+
+	filename := filepath.Join(t.TempDir(), "sqldoc_gen.go")
+	code, _, stderr = execute(t, "", "gen", "-s", "testdata/sql.yaml", "-p", "sample", "-tags", "-o", filename)
+	assert.Equal(t, 0, code, stderr)
+	result, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(result), "var DocJig = mdd.NewDocJigFromTags[Doc]()\n")
+}
+
+func TestUnknownCommand(t *testing.T) {
+	code, _, stderr := execute(t, "", "unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "unknown command: unknown")
+}
+
+func TestInfer(t *testing.T) {
+	code, stdout, stderr := execute(t, "", "infer", "testdata/ok.md", "testdata/ng.md")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "pattern: CRUD Matrix\n")
+
+	code, stdout, stderr = execute(t, "", "infer", "-f", "go", "-p", "sample", "testdata/ok.md")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "var DocJig = mdd.NewDocJig[Doc]()\n")
+}
+
+func TestTangle(t *testing.T) {
+	dir := t.TempDir()
+	code, stdout, stderr := execute(t, "", "tangle", "-o", dir, "-line", "testdata/tangle/*.md")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, filepath.Join(dir, "hello", "main.go")+"\n", stdout)
+	c, err := os.ReadFile(filepath.Join(dir, "hello", "main.go"))
+	assert.NoError(t, err)
+	// the document is outside of the output directory (temp dir), so the path is absolute
+	doc, err := filepath.Abs(filepath.Join("testdata", "tangle", "hello.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "//line "+doc+":4\npackage main\n\nfunc main() {\n"+
+		"//line "+doc+":14\n\tprintln(\"hello\")\n"+
+		"//line "+doc+":8\n}\n", string(c))
+}
+
+func TestTangle_OutsideDir(t *testing.T) {
+	abs, err := filepath.Abs(filepath.Join("testdata", "tangle"))
+	assert.NoError(t, err)
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir("testdata"))
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+
+	tests := []struct {
+		name    string
+		pattern string
+		doc     string
+	}{
+		{
+			name:    "parent dir",
+			pattern: "../testdata/tangle/*.md",
+			doc:     filepath.Join(abs, "hello.md"),
+		},
+		{
+			name:    "absolute path",
+			pattern: filepath.Join(abs, "*.md"),
+			doc:     filepath.Join(abs, "hello.md"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			code, _, stderr := execute(t, "", "tangle", "-o", dir, "-line", tc.pattern)
+			assert.Equal(t, 0, code, stderr)
+			c, err := os.ReadFile(filepath.Join(dir, "hello", "main.go"))
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(c), "//line "+tc.doc+":4\npackage main\n"), string(c))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/shibukawa/formatdata-go"
+	"github.com/shibukawa/mdd-go"
+)
+
+func loadJig(schemaPath string) (*mdd.DocJig[map[string]any], error) {
+	if schemaPath == "" {
+		return nil, errors.New("schema file is required (-s)")
+	}
+	schema, err := mdd.LoadSchemaFile(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	return mdd.NewDocJigFromSchema(schema)
+}
+
+func runParse(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("s", "", "jig schema file (YAML/JSON)")
+	format := fs.String("f", "json", "output format (json, yaml)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var outputFormat formatdata.OutputFormat
+	switch *format {
+	case "json":
+		outputFormat = formatdata.JSON
+	case "yaml":
+		outputFormat = formatdata.YAML
+	default:
+		fmt.Fprintf(stderr, "unknown format: %s\n", *format)
+		return 2
+	}
+	jig, err := loadJig(*schemaPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var result any
+	if fs.NArg() == 0 {
+		doc, err := jig.Parse(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		result = doc
+	} else if fs.NArg() == 1 {
+		doc, err := jig.ParseFile(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", fs.Arg(0), err)
+			return 1
+		}
+		result = doc
+	} else {
+		docs := make(map[string]any)
+		for _, filename := range fs.Args() {
+			doc, err := jig.ParseFile(filename)
+			if err != nil {
+				fmt.Fprintf(stderr, "%s: %v\n", filename, err)
+				return 1
+			}
+			docs[filepath.ToSlash(filename)] = doc
+		}
+		result = docs
+	}
+	if err := formatdata.FormatDataTo(result, stdout, formatdata.Opt{OutputFormat: outputFormat}); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/shibukawa/mdd-go"
+)
+
+func runTangle(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tangle", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", ".", "output directory")
+	line := fs.Bool("line", false, "emit line directives that point to markdown files")
+	dryRun := fs.Bool("n", false, "print file names without writing files")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "file patterns are required")
+		return 2
+	}
+	files, err := mdd.TangleGlob(mdd.TangleOption{LineDirectives: *line, OutputDir: *output}, fs.Args()...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if !*dryRun {
+		if err := mdd.WriteTangledFiles(*output, files); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	for _, f := range files {
+		fmt.Fprintln(stdout, filepath.Join(*output, filepath.FromSlash(f.Name)))
+	}
+	return 0
+}
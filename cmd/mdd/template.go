@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/shibukawa/mdd-go"
+)
+
+func runTemplate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("template", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("s", "", "jig schema file (YAML/JSON)")
+	lang := fs.String("lang", "", "language of template")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	jig, err := loadJig(*schemaPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	err = jig.GenerateTemplate(stdout, mdd.GenerateOption{
+		Language: *lang,
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
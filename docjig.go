@@ -1,7 +1,6 @@
 package mdd
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"io/fs"
@@ -9,14 +8,23 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 
-	"github.com/future-architect/tagscanner/runtimescan"
 	"github.com/russross/blackfriday/v2"
 )
 
 type alias struct {
-	lang  string
-	label string
+	lang     string
+	label    string
+	lowLabel string
+}
+
+func newAlias(lang, label string) *alias {
+	return &alias{
+		lang:     lang,
+		label:    label,
+		lowLabel: strings.ToLower(label),
+	}
 }
 
 // PostProcessHook is interface for document.
@@ -76,6 +84,19 @@ type DocJig[T any] struct {
 	root        *Layout[T]
 	DefaultLang string
 	aliases     map[string][]*alias
+	converters  map[reflect.Type]func(string) (any, error)
+	bools       *boolVocabulary
+
+	guessLanguage func(code string) string
+
+	mu         sync.Mutex
+	compiled   bool
+	boolMap    map[string]bool
+	aliasIndex map[string]string
+
+	// for untyped jig (see NewDocJigFromSchema)
+	docType     reflect.Type
+	fromDocType func(v reflect.Value) any
 }
 
 // NewDocJig is entry point function of this library
@@ -83,8 +104,11 @@ func NewDocJig[T any]() *DocJig[T] {
 	j := &DocJig[T]{
 		DefaultLang: "en",
 		aliases:     make(map[string][]*alias),
+		converters:  make(map[reflect.Type]func(string) (any, error)),
 	}
 
+	j.bools = newBoolVocabulary(j.invalidate)
+
 	j.root = &Layout[T]{
 		j:     j,
 		Level: 1,
@@ -92,6 +116,29 @@ func NewDocJig[T any]() *DocJig[T] {
 	return j
 }
 
+func (j *DocJig[T]) rootType() reflect.Type {
+	if j.docType != nil {
+		return j.docType
+	}
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// RegisterConverter registers a function that converts text into the value of type t
+//
+// It is used for labels, options, code fences and table cells whose field type is t (or *t).
+// The returned value should be assignable to t:
+//
+//	jig.RegisterConverter(reflect.TypeOf(time.Duration(0)), func(s string) (any, error) {
+//		return time.ParseDuration(s)
+//	})
+//
+// Types that implement [encoding.TextUnmarshaler] are supported without converter.
+// [StructField.As] has priority over registered converters.
+func (j *DocJig[T]) RegisterConverter(t reflect.Type, convert func(value string) (any, error)) {
+	j.invalidate()
+	j.converters[t] = convert
+}
+
 // Alias specify label alias or translation
 //
 // Basically Translation and standard alias is not different when parsing:
@@ -100,19 +147,18 @@ func NewDocJig[T any]() *DocJig[T] {
 //	jig.Alias("Table").Lang("ja", "表")
 //
 // Language is used for [DocJig.GenerateTemplate].
+//
+// Aliases are also applied to languages of code fences:
+//
+//	jig.Alias("postgres", "psql", "pgsql")
 func (j *DocJig[T]) Alias(primaryLabel string, aliases ...string) *Alias[T] {
+	j.invalidate()
 	lowLabel := strings.ToLower(primaryLabel)
 	if _, ok := j.aliases[lowLabel]; !ok {
-		j.aliases[lowLabel] = append(j.aliases[lowLabel], &alias{
-			lang:  j.DefaultLang,
-			label: primaryLabel,
-		})
+		j.aliases[lowLabel] = append(j.aliases[lowLabel], newAlias(j.DefaultLang, primaryLabel))
 	}
 	for _, a := range aliases {
-		j.aliases[lowLabel] = append(j.aliases[lowLabel], &alias{
-			lang:  j.DefaultLang,
-			label: a,
-		})
+		j.aliases[lowLabel] = append(j.aliases[lowLabel], newAlias(j.DefaultLang, a))
 	}
 	return &Alias[T]{
 		parent:       j,
@@ -133,7 +179,7 @@ func (j *DocJig[T]) matchLabel(pattern, actualLabel string) (suffix string, ok b
 	}
 
 	for _, a := range j.aliases[pl] {
-		if strings.HasPrefix(vl, strings.ToLower(a.label)) {
+		if strings.HasPrefix(vl, a.lowLabel) {
 			actualLabel = actualLabel[len(a.label):]
 			suffix = strings.TrimLeft(actualLabel, " :\t")
 			ok = true
@@ -156,20 +202,6 @@ func (j *DocJig[T]) findTranslation(pattern, lang string) string {
 	return pattern
 }
 
-func (j *DocJig[T]) translateToPrimaryKey(variant string, searchTargets []string) (index int, key string, ok bool) {
-	for i, target := range searchTargets {
-		for _, a := range j.aliases[target] {
-			if variant == strings.ToLower(a.label) {
-				index = i
-				ok = true
-				key = j.aliases[target][0].label
-				return
-			}
-		}
-	}
-	return -1, "", false
-}
-
 // Alias is used to absorb orthographical variants or translation
 //
 // This object is created by [DocJig.Alias] method.
@@ -180,11 +212,9 @@ type Alias[T any] struct {
 
 // Lang specifies word in other language
 func (i *Alias[T]) Lang(lang string, aliases ...string) *Alias[T] {
+	i.parent.invalidate()
 	for _, a := range aliases {
-		i.parent.aliases[i.primaryLabel] = append(i.parent.aliases[i.primaryLabel], &alias{
-			lang:  lang,
-			label: a,
-		})
+		i.parent.aliases[i.primaryLabel] = append(i.parent.aliases[i.primaryLabel], newAlias(lang, a))
 	}
 
 	return i
@@ -239,14 +269,38 @@ func (j *DocJig[T]) Parse(r io.Reader) (*T, error) {
 //	    return jig.ParseString(r)
 //	}
 func (j *DocJig[T]) ParseString(src string) (*T, error) {
+	return j.parse(src, nil)
+}
+
+// parse parses markdown. inc is nil if the document is not a file.
+func (j *DocJig[T]) parse(src string, inc *includer) (*T, error) {
+	j.compile()
+
 	var result T
 
-	rootResult := reflect.ValueOf(&result).Elem()
+	var rootResult reflect.Value
+	if j.docType != nil {
+		rootResult = reflect.New(j.docType).Elem()
+	} else {
+		rootResult = reflect.ValueOf(&result).Elem()
+	}
 
 	// var current reflect.Value
 
 	parser := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions))
-	root := parser.Parse([]byte(src))
+	// blackfriday doesn't recognize blocks with CRLF. Code fences get CRLF back (see CodeFence.NormalizeNewline).
+	crlf := strings.Contains(src, "\r\n")
+	parseSrc := src
+	if crlf {
+		parseSrc = strings.ReplaceAll(src, "\r\n", "\n")
+	}
+	root := parser.Parse([]byte(parseSrc))
+	locator := newFenceLocator(src)
+	var srcLines []string
+	if crlf {
+		srcLines = strings.Split(src, "\n")
+	}
+	nextLine := 0 // index of srcLines to search indented code blocks
 
 	node := root.FirstChild
 	stack := make([]*Layout[T], 7)
@@ -292,31 +346,56 @@ func (j *DocJig[T]) ParseString(src string) (*T, error) {
 				}
 				if labelMatched {
 					labels[currentLevel] = noOptLabel
-					err := assignValue(target, layout.labelFieldName, noOptLabel, "heading title", label)
+					err := assignValue(target, layout.labelRef, noOptLabel, layout.labelMerge, "heading title", label)
 					if err != nil {
 						return nil, err
 					}
 				}
 			}
 		case blackfriday.CodeBlock:
+			var fenceLine int
+			if node.IsFenced {
+				fenceLine = locator.find(node.Literal)
+			}
 			layout := stack[currentLevel]
 			if layout != nil {
 				target := targets[currentLevel]
 				label := labels[currentLevel]
 
-				lang, info := parseCodeBlockType(node.CodeBlockData.Info)
+				fi := parseFenceInfo(node.CodeBlockData.Info)
+				fi.line = fenceLine
+				if fi.lang == "" && j.guessLanguage != nil {
+					fi.lang = j.guessLanguage(string(node.Literal))
+				}
 
-				cf, ok := layout.findMatchedCodeFence(lang)
+				cf, ok := layout.findMatchedCodeFence(fi.lang, node.IsFenced)
 				if ok {
-					err := assignValue(target, cf.fieldName, strings.Trim(string(node.Literal), "\n"), "code fence", label)
-					if err != nil {
-						return nil, err
+					code := string(node.Literal)
+					if crlf {
+						// line endings are taken from the source to keep mixed ones
+						start := fenceLine
+						if !node.IsFenced {
+							start = findIndentedBlock(code, srcLines, nextLine)
+						} else if fenceLine == 0 {
+							start = -1
+						}
+						if start >= 0 {
+							code = restoreCR(code, srcLines, start)
+							nextLine = start + strings.Count(code, "\n")
+						} else {
+							code = strings.ReplaceAll(code, "\n", "\r\n")
+						}
 					}
-					err = assignValue(target, cf.languageFieldName, lang, "code fence's lang", label)
-					if err != nil {
-						return nil, err
+					if name := fi.attrs["include"]; name != "" {
+						var err error
+						code, fi.lines, err = inc.load(name, fi.attrs["lines"], fi.attrs["region"])
+						if err != nil {
+							return nil, fmt.Errorf("code fence%s (inside '%s' section): %w", atLine(fi.line), label, err)
+						}
+						fi.include = name
 					}
-					err = assignValue(target, cf.infoFieldName, info, "code fence's info", label)
+					code, fi.offset = cf.content(code)
+					err := cf.assign(target, code, fi, label, &result)
 					if err != nil {
 						return nil, err
 					}
@@ -327,9 +406,9 @@ func (j *DocJig[T]) ParseString(src string) (*T, error) {
 			if layout != nil {
 				target := targets[currentLevel]
 				label := labels[currentLevel]
-				cells, key2column := parseTable(node)
 				if layout.table != nil {
-					err := layout.table.assignCells(target, cells, key2column, label, &result)
+					headers, rows := parseTable(node)
+					err := layout.table.assignCells(target, headers, rows, label, &result)
 					if err != nil {
 						return nil, err
 					}
@@ -339,67 +418,26 @@ func (j *DocJig[T]) ParseString(src string) (*T, error) {
 		node = node.Next
 	}
 
-	m := reflect.ValueOf(&result).MethodByName("PostProcess")
-	if m.IsValid() {
-		errs := m.Call(nil)
-		if len(errs) > 0 {
-			err, ok := errs[0].Interface().(error)
-			if ok {
-				return nil, err
-			}
-		}
-	}
-
-	return &result, nil
-}
-
-func assignValue(target reflect.Value, fieldName string, value any, context, label string) error {
-	if fieldName == "" {
-		return nil
+	if j.docType != nil {
+		result = j.fromDocType(rootResult).(T)
 	}
-	field := getFieldByName(target, fieldName)
 
-	if field.IsValid() {
-		if !field.IsZero() {
-			return fmt.Errorf("field '%s' for %s is already filled (inside '%s' section)", fieldName, context, label)
+	switch h := any(&result).(type) {
+	case PostProcessHook:
+		h.PostProcess()
+	case interface{ PostProcess() error }:
+		if err := h.PostProcess(); err != nil {
+			return nil, err
 		}
-		return runtimescan.FuzzyAssign(field.Addr().Interface(), value)
-	} else {
-		return fmt.Errorf("%s doesn't have field '%s' for %s (inside '%s' section)", target.Type(), fieldName, context, label)
 	}
-}
 
-func getFieldByName(target reflect.Value, fieldName string) reflect.Value {
-	if target.Kind() == reflect.Pointer {
-		return target.Elem().FieldByName(fieldName)
-	} else {
-		return target.FieldByName(fieldName)
-	}
+	return &result, nil
 }
 
-// parseCodeBlockType parses code block first line
-//
-// It returns ("sql", ""):
-//
-//	```sql
-//	```
-//
-// It returns ("csv", "users"):
-//
-//	```csv :users
-//	```
+// parseCodeBlockType returns language and info of code block. See parseFenceInfo.
 func parseCodeBlockType(info []byte) (mode string, targetname string) {
-	blocks := bytes.SplitN(info, []byte{':'}, 2)
-	switch len(blocks) {
-	case 0:
-		return "", ""
-	case 1:
-		mode = strings.TrimSpace(string(blocks[0]))
-	case 2:
-		mode = strings.TrimSpace(string(blocks[0]))
-		targetname = strings.TrimSpace(string(blocks[1]))
-	}
-	return
+	r := parseFenceInfo(info)
+	return r.lang, r.info
 }
 
 // Parse method is an entry point of your DocJig instance for
@@ -410,13 +448,15 @@ func parseCodeBlockType(info []byte) (mode string, targetname string) {
 //	func ParseFile(filepath string) (*YourDocument, error) {
 //	    return jig.ParseFile(filepath)
 //	}
+//
+// Code fences that have include attribute (```sql include=find_user.sql) read files relative to filepath.
+// Files outside the directory of filepath can't be included.
 func (t *DocJig[T]) ParseFile(filepath string) (*T, error) {
-	o, err := os.Open(filepath)
+	src, err := os.ReadFile(filepath)
 	if err != nil {
 		return nil, err
 	}
-	defer o.Close()
-	return t.Parse(o)
+	return t.parse(string(src), newOSIncluder(filepath))
 }
 
 // Parse method is an entry point of your DocJig instance for
@@ -514,6 +554,9 @@ func (t *DocJig[T]) ParseGlob(patterns ...string) (map[string]*T, error) {
 //	func ParseFS(fsys fs.FS, patterns ...string)) (map[string]*YourDocument, error) {
 //	    return jig.ParseFS(fsys, patterns...)
 //	}
+//
+// Code fences that have include attribute read files in fsys relative to the document.
+// Files outside the directory of the document can't be included.
 func (t *DocJig[T]) ParseFS(fsys fs.FS, patterns ...string) (map[string]*T, error) {
 	var filenames []string
 	for _, pattern := range patterns {
@@ -539,7 +582,7 @@ func (t *DocJig[T]) ParseFS(fsys fs.FS, patterns ...string) (map[string]*T, erro
 		if err != nil {
 			return nil, err
 		}
-		parsed, err := t.ParseString(string(c))
+		parsed, err := t.parse(string(c), newFSIncluder(fsys, filename))
 		if err != nil {
 			return nil, err
 		}
@@ -580,50 +623,36 @@ func (t *DocJig[T]) MustParseFS(fsys fs.FS, patterns ...string) map[string]*T {
 	return result
 }
 
-// parseTable parses table and convert to slice of map
-func parseTable(node *blackfriday.Node) (cells []map[string]string, key2column map[string]int) {
+// parseTable parses table and returns header labels and rows of cells
+//
+// Cells of each row are ordered as same as headers.
+func parseTable(node *blackfriday.Node) (headers []string, rows [][]string) {
 	headMode := false
-	var column int
-	var row int
-	keyMap := make(map[int]string)
-	key2column = make(map[string]int)
-
-	var currentRow map[string]string
+	var currentRow []string
 
 	node.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
 		switch node.Type {
 		case blackfriday.TableCell:
 			text := plainTextRenderer(node)
 			if headMode {
-				keyMap[column] = strings.ToLower(text)
-				key2column[text] = column
-			} else {
-				if key, ok := keyMap[column]; ok {
-					currentRow[key] = text
-				}
+				headers = append(headers, text)
+			} else if len(currentRow) < len(headers) {
+				currentRow = append(currentRow, text)
 			}
-			column++
 			return blackfriday.SkipChildren
 		case blackfriday.TableHead:
-			if entering {
-				headMode = true
-			} else {
-				headMode = false
-			}
+			headMode = entering
 		case blackfriday.TableRow:
-			column = 0
+			if headMode {
+				break
+			}
 			if entering {
-				if headMode {
-				} else {
-					currentRow = make(map[string]string)
-				}
+				currentRow = make([]string, 0, len(headers))
 			} else {
-				row++
-				if headMode {
-					// todo error check
-				} else {
-					cells = append(cells, currentRow)
+				for len(currentRow) < len(headers) {
+					currentRow = append(currentRow, "")
 				}
+				rows = append(rows, currentRow)
 			}
 		}
 		return blackfriday.GoToNext
@@ -0,0 +1,139 @@
+package mdd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type benchRow struct {
+	Table       string
+	C           bool
+	R           bool
+	U           bool
+	D           bool
+	Description string
+}
+
+type benchQuery struct {
+	Name      string
+	Code      string
+	Lang      string
+	Info      string
+	Important bool
+	Owner     string
+	Rows      []benchRow
+}
+
+type benchDoc struct {
+	Name    string
+	Summary string
+	Queries []benchQuery
+}
+
+func newBenchJig() *DocJig[benchDoc] {
+	jig := NewDocJig[benchDoc]()
+	jig.Alias("Description", "Desc", "Detail").Lang("ja", "説明", "詳細")
+	jig.Alias("Table").Lang("ja", "テーブル", "表")
+	jig.Alias("Query").Lang("ja", "クエリー")
+	root := jig.Root()
+	root.Label("Name")
+	root.CodeFence("Summary", "txt")
+	queries := root.Children("Queries", "Query")
+	queries.Label("Name")
+	queries.Option("Important")
+	queries.Option("Owner")
+	queries.CodeFence("Code", "sql").Language("Lang").Info("Info")
+	crud := queries.Table("Rows")
+	crud.Field("Table").Required()
+	crud.Field("C")
+	crud.Field("R")
+	crud.Field("U")
+	crud.Field("D")
+	crud.Field("Description")
+	return jig
+}
+
+// generateBenchDoc generates a markdown document that has the given number of
+// sections and rows per table.
+func generateBenchDoc(sections, rows int) string {
+	var b strings.Builder
+	b.WriteString("# Generated Document\n\n")
+	b.WriteString("```txt\nsummary of the generated document\n```\n\n")
+	for s := 0; s < sections; s++ {
+		if s%2 == 0 {
+			fmt.Fprintf(&b, "## Query: query%d (Important, Owner=team%d)\n\n", s, s)
+		} else {
+			fmt.Fprintf(&b, "## クエリー: query%d (Owner=team%d)\n\n", s, s)
+		}
+		fmt.Fprintf(&b, "```sql :q%d\nselect * from table%d where id = /*id*/1;\n```\n\n", s, s)
+		if s%2 == 0 {
+			b.WriteString("| Table | C | R | U | D | Description |\n")
+		} else {
+			b.WriteString("| テーブル | C | R | U | D | 説明 |\n")
+		}
+		b.WriteString("|-------|---|---|---|---|-------------|\n")
+		for r := 0; r < rows; r++ {
+			fmt.Fprintf(&b, "| table%d |   | X |   |   | row %d |\n", r, r)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func benchmarkParseString(b *testing.B, sections, rows int) {
+	jig := newBenchJig()
+	src := generateBenchDoc(sections, rows)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := jig.ParseString(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseString_Small(b *testing.B) {
+	benchmarkParseString(b, 5, 5)
+}
+
+func BenchmarkParseString_ManySections(b *testing.B) {
+	benchmarkParseString(b, 500, 3)
+}
+
+func BenchmarkParseString_LargeTables(b *testing.B) {
+	benchmarkParseString(b, 10, 1000)
+}
+
+func BenchmarkParseFS_Corpus(b *testing.B) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 1000; i++ {
+		fsys[fmt.Sprintf("docs/doc%04d.md", i)] = &fstest.MapFile{
+			Data: []byte(generateBenchDoc(5, 10)),
+		}
+	}
+	jig := newBenchJig()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := jig.ParseFS(fsys, "docs/*.md"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBenchDocIsParsable(t *testing.T) {
+	jig := newBenchJig()
+	doc, err := jig.ParseString(generateBenchDoc(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Queries) != 2 || len(doc.Queries[1].Rows) != 2 {
+		t.Fatalf("unexpected result: %#v", doc)
+	}
+	if doc.Queries[1].Rows[1].Description != "row 1" || !doc.Queries[0].Important || doc.Queries[1].Owner != "team1" {
+		t.Fatalf("unexpected result: %#v", doc.Queries)
+	}
+}
@@ -1,9 +1,14 @@
 package mdd
 
 import (
+	"bytes"
 	"embed"
 	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -265,3 +270,198 @@ func TestPostProcessNG(t *testing.T) {
 	assert.Error(t, err, PostProcessError.Error())
 	assert.Nil(t, got)
 }
+
+func TestRedefineAfterParse(t *testing.T) {
+	type Doc struct {
+		Title string
+		Code  string
+	}
+
+	jig := NewDocJig[Doc]()
+	root := jig.Root()
+	root.Label("Title")
+
+	src := "# Title\n\n```sh\necho hello\n```\n"
+	got, err := jig.ParseString(src)
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{Title: "Title"}, got)
+
+	// compiled plan should be updated
+	root.CodeFence("Code")
+	got, err = jig.ParseString(src)
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{Title: "Title", Code: "echo hello"}, got)
+}
+
+type testLevel int
+
+const (
+	testLevelLow testLevel = iota + 1
+	testLevelHigh
+)
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = testLevelLow
+	case "high":
+		*l = testLevelHigh
+	default:
+		return fmt.Errorf("unknown level: %s", text)
+	}
+	return nil
+}
+
+type testUserID string
+
+func TestCustomValueDecoding(t *testing.T) {
+	type Row struct {
+		ID      testUserID
+		Since   time.Time
+		Timeout time.Duration
+		Home    *url.URL
+	}
+
+	type Doc struct {
+		Level testLevel
+		Retry *time.Duration
+		Rows  []Row
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.RegisterConverter(reflect.TypeOf(time.Duration(0)), func(s string) (any, error) {
+		return time.ParseDuration(s)
+	})
+	jig.RegisterConverter(reflect.TypeOf(url.URL{}), func(s string) (any, error) {
+		return url.Parse(s)
+	})
+	jig.RegisterConverter(reflect.TypeOf(testUserID("")), func(s string) (any, error) {
+		return testUserID("user-" + s), nil
+	})
+	root := jig.Root()
+	root.Label("Level")
+	root.Option("Retry", "retry")
+	table := root.Table("Rows")
+	table.Field("ID")
+	table.Field("Since")
+	table.Field("Timeout")
+	table.Field("Home")
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# high (retry=3s)
+
+	| ID | Since                | Timeout | Home               |
+	|----|----------------------|---------|--------------------|
+	| 10 | 2024-01-02T03:04:05Z | 1m30s   | https://example.com |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, testLevelHigh, got.Level)
+	if assert.NotNil(t, got.Retry) {
+		assert.Equal(t, 3*time.Second, *got.Retry)
+	}
+	if assert.Len(t, got.Rows, 1) {
+		row := got.Rows[0]
+		assert.Equal(t, testUserID("user-10"), row.ID)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), row.Since)
+		assert.Equal(t, 90*time.Second, row.Timeout)
+		if assert.NotNil(t, row.Home) {
+			assert.Equal(t, "example.com", row.Home.Host)
+		}
+	}
+
+	_, err = jig.ParseString("# middle\n")
+	assert.EqualError(t, err, "unknown level: middle")
+}
+
+func TestCustomValueDecoding_TypeMismatch(t *testing.T) {
+	type Doc struct {
+		Name testUserID
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.RegisterConverter(reflect.TypeOf(testUserID("")), func(s string) (any, error) {
+		return 10, nil
+	})
+	jig.Root().Label("Name")
+
+	_, err := jig.ParseString("# Title\n")
+	assert.EqualError(t, err, "converted value type int is not assignable to mdd.testUserID")
+}
+
+func TestDottedFieldPath(t *testing.T) {
+	type Limits struct {
+		MaxRows int
+	}
+
+	type Meta struct {
+		Title  string
+		Author string
+		SQL    string
+	}
+
+	type Owner struct {
+		Name string
+	}
+
+	type Row struct {
+		Table string
+		Owner *Owner
+	}
+
+	type Doc struct {
+		Meta   Meta
+		Limits *Limits
+		Rows   []Row
+	}
+
+	jig := NewDocJig[Doc]()
+	root := jig.Root()
+	root.Label("Meta.Title")
+	root.Option("Limits.MaxRows")
+	root.CodeFence("Meta.SQL", "sql")
+	table := root.Table("Rows")
+	table.Field("Table")
+	table.Field("Owner.Name", "Owner")
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Find Users (MaxRows=100)
+
+	~~~sql
+	select * from users;
+	~~~
+
+	| Table  | Owner |
+	|--------|-------|
+	| users  | bob   |
+	| groups |       |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{
+		Meta: Meta{
+			Title: "Find Users",
+			SQL:   "select * from users;",
+		},
+		Limits: &Limits{MaxRows: 100},
+		Rows: []Row{
+			{Table: "users", Owner: &Owner{Name: "bob"}},
+			{Table: "groups"},
+		},
+	}, got)
+
+	var b bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&b))
+	assert.Contains(t, b.String(), "| Table | Owner |")
+}
+
+func TestDottedFieldPath_Invalid(t *testing.T) {
+	type Doc struct {
+		Meta struct {
+			Title string
+		}
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Root().Label("Meta.Name")
+	_, err := jig.ParseString("# Title\n")
+	assert.EqualError(t, err, "mdd.Doc doesn't have field 'Meta.Name' for heading title (inside 'Title' section)")
+}
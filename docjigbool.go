@@ -0,0 +1,124 @@
+package mdd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoolWords defines words that represent true or false in table cells and heading options
+//
+// It is returned by [DocJig.Bool] (jig level), [StructField.Bool] and [Option.Bool] (per field).
+// Words are compared case-insensitively. Like [Alias.Lang], words can be registered per language
+// and the language is used for [DocJig.GenerateTemplate]:
+//
+//	jig.Bool().True("✓").False("-").Lang("ja").True("有").False("無")
+//
+// Jig level words extend the default vocabulary (true, yes, on, 1, x, o, ✓, ○, ●, [x] for true and
+// false, no, off, 0, -, ×, [ ] for false). Per field words replace it. "true"/"false" are always
+// accepted. Unknown words are reported as errors.
+//
+// Blank cells are false by default. They are converted by the vocabulary too, so register "" to
+// change it (e.g. when only "-" means false):
+//
+//	table.Field("Required").Bool().True("", "yes").False("-")
+//
+// The vocabulary is used by table cells (including [FieldGroup]) and heading options. List items are not
+// bound to fields by [DocJig], so they are out of scope.
+type BoolWords struct {
+	vocab *boolVocabulary
+	lang  string
+}
+
+// True registers words that mean true
+func (b *BoolWords) True(words ...string) *BoolWords {
+	b.vocab.add(b.lang, true, words)
+	return b
+}
+
+// False registers words that mean false
+func (b *BoolWords) False(words ...string) *BoolWords {
+	b.vocab.add(b.lang, false, words)
+	return b
+}
+
+// Lang returns [BoolWords] to register words of other language
+func (b *BoolWords) Lang(lang string) *BoolWords {
+	return &BoolWords{
+		vocab: b.vocab,
+		lang:  lang,
+	}
+}
+
+type boolWord struct {
+	lang  string
+	word  string
+	value bool
+}
+
+type boolVocabulary struct {
+	words    []boolWord
+	onChange func()
+}
+
+func newBoolVocabulary(onChange func()) *boolVocabulary {
+	return &boolVocabulary{onChange: onChange}
+}
+
+func (v *boolVocabulary) add(lang string, value bool, words []string) {
+	v.onChange()
+	for _, w := range words {
+		v.words = append(v.words, boolWord{lang: lang, word: w, value: value})
+	}
+}
+
+var defaultBoolWords = map[string]bool{
+	"true": true, "yes": true, "on": true, "1": true, "x": true, "o": true,
+	"✓": true, "✔": true, "○": true, "◯": true, "●": true, "[x]": true,
+	"false": false, "no": false, "off": false, "0": false, "-": false,
+	"×": false, "✗": false, "[ ]": false, "": false,
+}
+
+var minimumBoolWords = map[string]bool{
+	"true": true, "false": false, "": false,
+}
+
+// table returns lower-cased word to value map. Later words override base and earlier ones.
+func (v *boolVocabulary) table(base map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(base)+len(v.words))
+	for k, b := range base {
+		result[k] = b
+	}
+	for _, w := range v.words {
+		result[strings.ToLower(strings.TrimSpace(w.word))] = w.value
+	}
+	return result
+}
+
+// word returns the first registered word of the language for template
+func (v *boolVocabulary) word(value bool, lang string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	for _, w := range v.words {
+		if w.lang == lang && w.value == value {
+			return w.word, true
+		}
+	}
+	return "", false
+}
+
+func parseBool(table map[string]bool, value string) (bool, error) {
+	b, ok := table[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return false, fmt.Errorf("unknown boolean value '%s'", value)
+	}
+	return b, nil
+}
+
+// Bool returns jig level [BoolWords]
+func (j *DocJig[T]) Bool() *BoolWords {
+	return &BoolWords{
+		vocab: j.bools,
+		lang:  j.DefaultLang,
+	}
+}
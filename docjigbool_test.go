@@ -0,0 +1,231 @@
+package mdd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolWords(t *testing.T) {
+	type CRUD struct {
+		Table string
+		C     bool
+		R     bool
+		Must  bool
+	}
+
+	type Doc struct {
+		Name string
+		Safe bool
+		CRUD []CRUD
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "default words",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					root.Label("Name")
+					root.Option("Safe")
+					table := root.Table("CRUD")
+					table.Field("Table")
+					table.Field("C")
+					table.Field("R")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query (Safe=yes)
+
+				| Table  | C  | R   |
+				|--------|----|-----|
+				| users  | ✓  | [x] |
+				| groups | -  |     |
+				`),
+			},
+			want: &Doc{
+				Name: "Query",
+				Safe: true,
+				CRUD: []CRUD{
+					{Table: "users", C: true, R: true},
+					{Table: "groups"},
+				},
+			},
+		},
+		{
+			name: "jig level words with l10n",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Bool().True("Y").Lang("ja").True("有").False("無")
+					root := jig.Root()
+					root.Label("Name")
+					table := root.Table("CRUD")
+					table.Field("Table")
+					table.Field("C")
+					table.Field("R")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| Table  | C | R  |
+				|--------|---|----|
+				| users  | 有 | y  |
+				| groups | 無 | no |
+				`),
+			},
+			want: &Doc{
+				Name: "Query",
+				CRUD: []CRUD{
+					{Table: "users", C: true, R: true},
+					{Table: "groups"},
+				},
+			},
+		},
+		{
+			name: "per field words replace defaults",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					root.Label("Name")
+					table := root.Table("CRUD")
+					table.Field("Table")
+					table.Field("Must").Bool().True("MUST").False("MAY")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| Table  | Must |
+				|--------|------|
+				| users  | X    |
+				`),
+			},
+			wantErr: "can't convert value 'X' at row 1, column 'Must' of table 'CRUD' (inside 'Query' section): unknown boolean value 'X'",
+		},
+		{
+			name: "blank cells by words",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Bool().True("")
+					root := jig.Root()
+					root.Label("Name")
+					table := root.Table("CRUD")
+					table.Field("Table")
+					table.Field("C")
+					table.Field("Must").Bool().True("MUST").False("")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| Table  | C | Must |
+				|--------|---|------|
+				| users  |   |      |
+				| groups | - | MUST |
+				`),
+			},
+			want: &Doc{
+				Name: "Query",
+				CRUD: []CRUD{
+					{Table: "users", C: true},
+					{Table: "groups", Must: true},
+				},
+			},
+		},
+		{
+			name: "unknown word in option",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					root.Label("Name")
+					root.Option("Safe")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query (Safe=maybe)
+				`),
+			},
+			wantErr: "unknown boolean value 'maybe'",
+		},
+		{
+			name: "flag option with per option words",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					root.Label("Name")
+					root.Option("Safe").Bool().True("safe").False("unsafe")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query (Safe)
+				`),
+			},
+			want: &Doc{
+				Name: "Query",
+				Safe: true,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBoolWords_Generate(t *testing.T) {
+	type Row struct {
+		Table string
+		R     bool
+	}
+
+	type Doc struct {
+		CRUD []Row
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Bool().True("X").False("").Lang("ja").True("有").False("無")
+	table := jig.Root().Table("CRUD")
+	table.Field("Table").Samples("users", "groups")
+	table.Field("R").Samples(true, false)
+
+	var en bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&en))
+	assert.Equal(t, TrimIndent(t, `
+	# [Title]
+
+	| Table  | R |
+	|--------|---|
+	| users  | X |
+	| groups |   |
+	`), strings.TrimRight(en.String(), "\n"))
+
+	var ja bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&ja, GenerateOption{Language: "ja"}))
+	assert.Contains(t, ja.String(), "| users  | 有 |")
+}
@@ -3,24 +3,181 @@ package mdd
 import (
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
+)
+
+// Snippet is a code fence stored into []Snippet field by [Layout.CodeFence]
+type Snippet struct {
+	Code  string
+	Lang  string
+	Info  string
+	Attrs map[string]string // attributes like title="main.go", {3-5}, #id, .class (nil if none)
+}
+
+var snippetType = reflect.TypeOf(Snippet{})
+
+// fenceKind is the kind of the field bound to code fences
+type fenceKind int
+
+const (
+	fenceValue    fenceKind = iota // single value (string or convertible type)
+	fenceList                      // []string: every matching fence in order
+	fenceSnippets                  // []Snippet: every matching fence with language and info
+	fenceMap                       // map[string]string: keyed by info string
+	fenceData                      // decoded by CSV, TSV, JSON or YAML
 )
 
 type CodeFence[T any] struct {
+	j                 *DocJig[T]
 	fieldName         string
 	targetLanguages   []string
+	defaultLanguages  bool // targetLanguages are defaults of the data format
 	languageFieldName string
 	infoFieldName     string
 	repeat            bool
 	sampleCode        string
 	sampleInfo        string
+	merge             merge
+	attrs             []*fenceAttr
+	data              dataFormat
+	table             *Table[T] // column mapping of CSV/TSV
+	validators        []func(code, lang, info string) error
+	ignoreIndented    bool
+	keepWhitespace    bool
+	dedent            bool
+	normalizeNewline  bool
+
+	// compiled plan
+	languages     []string // primary languages of targetLanguages
+	kind          fenceKind
+	fieldMerge    merge
+	ref           fieldRef
+	languageRef   fieldRef
+	infoRef       fieldRef
+	languageMerge merge
+	infoMerge     merge
+}
+
+type fenceAttr struct {
+	name      string
+	fieldName string
+
+	// compiled plan
+	ref   fieldRef
+	merge merge
+}
+
+// Attr stores the attribute of code fence info string into the field
+//
+//	```go title="main.go" {3-5} #example .hidden
+//
+// "{3-5}" is stored as "highlight" attribute, "#example" as "id" and ".hidden" as "class".
+// If the field is integer slice, line ranges like "3-5,7" are expanded into line numbers.
+// Attributes without value (```go linenums) are "true".
+func (cf *CodeFence[T]) Attr(name, fieldName string) *CodeFence[T] {
+	cf.j.invalidate()
+	for _, a := range cf.attrs {
+		if a.name == name {
+			a.fieldName = fieldName
+			return cf
+		}
+	}
+	cf.attrs = append(cf.attrs, &fenceAttr{name: name, fieldName: fieldName})
+	return cf
+}
+
+// Merge specifies the behavior when several code fences match this binding (default is [MergeError])
+//
+// separator is used by [MergeConcat] (default is "\n"). Language and info fields
+// keep the first value except [MergeError], [MergeLast] and [MergeAppend] with slice field.
+func (cf *CodeFence[T]) Merge(policy MergePolicy, separator ...string) *CodeFence[T] {
+	cf.j.invalidate()
+	cf.merge = newMerge(policy, separator)
+	return cf
+}
+
+// IgnoreIndented ignores indented code blocks. Only fenced code blocks are stored into this binding.
+func (cf *CodeFence[T]) IgnoreIndented() *CodeFence[T] {
+	cf.ignoreIndented = true
+	return cf
+}
+
+// KeepWhitespace keeps content exactly including leading blank lines and the trailing newline.
+// By default, leading and trailing newlines are trimmed.
+func (cf *CodeFence[T]) KeepWhitespace() *CodeFence[T] {
+	cf.keepWhitespace = true
+	return cf
+}
+
+// Dedent removes common leading spaces and tabs of lines
+func (cf *CodeFence[T]) Dedent() *CodeFence[T] {
+	cf.dedent = true
+	return cf
+}
+
+// NormalizeNewline converts CRLF into LF. Without it, code fences in CRLF documents
+// and included files keep CRLF.
+func (cf *CodeFence[T]) NormalizeNewline() *CodeFence[T] {
+	cf.normalizeNewline = true
+	return cf
+}
+
+// content applies whitespace options to code block content. offset is the number of lines trimmed from the head.
+func (cf *CodeFence[T]) content(code string) (result string, offset int) {
+	if cf.normalizeNewline {
+		code = strings.ReplaceAll(code, "\r\n", "\n")
+	}
+	if cf.dedent {
+		code = dedent(code)
+	}
+	if !cf.keepWhitespace {
+		trimmed := strings.TrimLeft(code, "\r\n")
+		offset = strings.Count(code[:len(code)-len(trimmed)], "\n")
+		code = strings.TrimRight(trimmed, "\r\n")
+	}
+	return code, offset
+}
+
+// dedent removes common indent of non-blank lines
+func dedent(code string) string {
+	lines := strings.Split(code, "\n")
+	var indent string
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		i := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			indent, first = i, false
+			continue
+		}
+		for !strings.HasPrefix(i, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+	if indent == "" {
+		return code
+	}
+	for n, l := range lines {
+		if strings.HasPrefix(l, indent) {
+			lines[n] = l[len(indent):]
+		} else {
+			lines[n] = strings.TrimLeft(l, " \t") // blank line
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (cf *CodeFence[T]) Language(fieldName string) *CodeFence[T] {
+	cf.j.invalidate()
 	cf.languageFieldName = fieldName
 	return cf
 }
 
 func (cf *CodeFence[T]) Info(fieldName string) *CodeFence[T] {
+	cf.j.invalidate()
 	cf.infoFieldName = fieldName
 	return cf
 }
@@ -35,11 +192,131 @@ func (cf *CodeFence[T]) SampleInfo(info string) *CodeFence[T] {
 	return cf
 }
 
+// compile resolves fields of t. Slice and map fields collect every matching fence.
+func (cf *CodeFence[T]) compile(t reflect.Type) {
+	r := resolveField(t, cf.fieldName)
+	cf.languages = make([]string, len(cf.targetLanguages))
+	for i, l := range cf.targetLanguages {
+		cf.languages[i] = cf.j.canonicalLanguage(l)
+	}
+	cf.kind = fenceValue
+	cf.fieldMerge = cf.merge
+	if cf.data != dataNone {
+		cf.kind = fenceData
+		if cf.table != nil {
+			cf.table.compile(t)
+		}
+	} else if ft := r.typ(t); ft != nil {
+		switch {
+		case ft.Kind() == reflect.Slice && ft.Elem() == snippetType:
+			cf.kind = fenceSnippets
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.String:
+			cf.kind = fenceList
+		case ft.Kind() == reflect.Map && ft.Key().Kind() == reflect.String && ft.Elem().Kind() == reflect.String:
+			cf.kind = fenceMap
+		}
+	}
+	if (cf.kind == fenceList || cf.kind == fenceSnippets || cf.kind == fenceMap) && cf.merge.policy == MergeError {
+		cf.fieldMerge = merge{policy: MergeAppend, separator: cf.merge.separator}
+	}
+	if cf.kind == fenceValue || cf.kind == fenceList {
+		cf.ref = cf.j.resolveMerge(t, cf.fieldName, nil, cf.fieldMerge)
+	} else {
+		cf.ref = r
+	}
+	cf.languageMerge = cf.fieldMerge.sub(resolveField(t, cf.languageFieldName).typ(t))
+	cf.infoMerge = cf.fieldMerge.sub(resolveField(t, cf.infoFieldName).typ(t))
+	cf.languageRef = cf.j.resolveMerge(t, cf.languageFieldName, nil, cf.languageMerge)
+	cf.infoRef = cf.j.resolveMerge(t, cf.infoFieldName, nil, cf.infoMerge)
+	for _, a := range cf.attrs {
+		ft := resolveField(t, a.fieldName).typ(t)
+		if ft != nil && ft.Kind() == reflect.Slice && isIntKind(ft.Elem().Kind()) {
+			a.merge = cf.fieldMerge.sub(nil)
+			a.ref = cf.j.resolve(t, a.fieldName, nil)
+			a.ref.set = lineRangesSetter(ft)
+		} else {
+			a.merge = cf.fieldMerge.sub(ft)
+			a.ref = cf.j.resolveMerge(t, a.fieldName, nil, a.merge)
+		}
+	}
+}
+
+// assign stores code fence content, language and info into target
+func (cf *CodeFence[T]) assign(target reflect.Value, code string, fi fenceInfo, label string, doc *T) error {
+	err := cf.validate(code, fi, label)
+	if err != nil {
+		return err
+	}
+	switch cf.kind {
+	case fenceData:
+		err = cf.decode(target, code, fi, label, doc)
+	case fenceSnippets:
+		field := cf.ref.field(target)
+		if !field.IsValid() {
+			return fmt.Errorf("%s doesn't have field '%s' for code fence (inside '%s' section)", target.Type(), cf.fieldName, label)
+		}
+		field.Set(reflect.Append(field, reflect.ValueOf(Snippet{Code: code, Lang: fi.lang, Info: fi.info, Attrs: fi.attrs})))
+	case fenceMap:
+		err = cf.assignMap(target, code, fi.info, label)
+	default:
+		err = assignValue(target, cf.ref, code, cf.fieldMerge, "code fence", label)
+	}
+	if err != nil {
+		return err
+	}
+	err = assignValue(target, cf.languageRef, fi.lang, cf.languageMerge, "code fence's lang", label)
+	if err != nil {
+		return err
+	}
+	err = assignValue(target, cf.infoRef, fi.info, cf.infoMerge, "code fence's info", label)
+	if err != nil {
+		return err
+	}
+	for _, a := range cf.attrs {
+		value, ok := fi.attrs[a.name]
+		if !ok {
+			continue
+		}
+		err = assignValue(target, a.ref, value, a.merge, "code fence's "+a.name+" attribute", label)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignMap stores code into map field with info string key. Merge policy is applied per key.
+func (cf *CodeFence[T]) assignMap(target reflect.Value, code, info, label string) error {
+	field := cf.ref.field(target)
+	if !field.IsValid() {
+		return fmt.Errorf("%s doesn't have field '%s' for code fence (inside '%s' section)", target.Type(), cf.fieldName, label)
+	}
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+	key := reflect.ValueOf(info).Convert(field.Type().Key())
+	if existing := field.MapIndex(key); existing.IsValid() {
+		switch cf.merge.policy {
+		case MergeFirst:
+			return nil
+		case MergeConcat:
+			code = existing.String() + cf.merge.separator + code
+		case MergeLast:
+		default:
+			return fmt.Errorf("field '%s' for code fence already has info '%s' (inside '%s' section)", cf.fieldName, info, label)
+		}
+	}
+	field.SetMapIndex(key, reflect.ValueOf(code).Convert(field.Type().Elem()))
+	return nil
+}
+
+// matchLanguage checks language of code fence. Aliases are translated by DocJig.Alias and the default language aliases.
 func (cf CodeFence[T]) matchLanguage(lang string) bool {
-	if len(cf.targetLanguages) == 0 {
+	if len(cf.languages) == 0 {
 		return true
 	}
-	for _, l := range cf.targetLanguages {
+	lang = cf.j.canonicalLanguage(lang)
+	for _, l := range cf.languages {
 		if l == lang {
 			return true
 		}
@@ -47,7 +324,7 @@ func (cf CodeFence[T]) matchLanguage(lang string) bool {
 	return false
 }
 
-func (cf CodeFence[T]) generateTemplate(w io.Writer) {
+func (cf CodeFence[T]) generateTemplate(w io.Writer, docLang string) {
 	var lang string
 	if len(cf.targetLanguages) > 0 {
 		lang = cf.targetLanguages[0]
@@ -55,6 +332,8 @@ func (cf CodeFence[T]) generateTemplate(w io.Writer) {
 	var code string
 	if cf.sampleCode != "" {
 		code = cf.sampleCode + "\n"
+	} else if cf.table != nil {
+		code = cf.table.headerLine(cf.data, docLang) + "\n"
 	}
 	fmt.Fprintf(w, "```%s%s\n%s```\n\n", lang, cf.sampleInfo, code)
 }
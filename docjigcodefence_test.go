@@ -217,6 +217,263 @@ func TestRepeatableCodeFence(t *testing.T) {
 	}
 }
 
+func TestCollectCodeFences(t *testing.T) {
+	type Doc struct {
+		Codes    []string
+		Snippets []Snippet
+		Files    map[string]string
+		Langs    []string
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+
+	src := TrimIndent(t, `
+	# Root Heading
+
+	~~~go :main.go
+	package main
+	~~~
+
+	Text between fences.
+
+	~~~go :util.go
+	package util
+	~~~
+
+	~~~sql
+	select 1;
+	~~~
+	`)
+
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "slice of string",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Codes", "go").Language("Langs")
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				Codes: []string{"package main", "package util"},
+				Langs: []string{"go", "go"},
+			},
+		},
+		{
+			name: "slice of snippet",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Snippets")
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				Snippets: []Snippet{
+					{Code: "package main", Lang: "go", Info: "main.go"},
+					{Code: "package util", Lang: "go", Info: "util.go"},
+					{Code: "select 1;", Lang: "sql"},
+				},
+			},
+		},
+		{
+			name: "map keyed by info",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Files", "go")
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				Files: map[string]string{
+					"main.go": "package main",
+					"util.go": "package util",
+				},
+			},
+		},
+		{
+			name: "map: duplicated info",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Files")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				~~~go :main.go
+				package main
+				~~~
+
+				~~~go :main.go
+				func main() {}
+				~~~
+				`),
+			},
+			wantErr: "field 'Files' for code fence already has info 'main.go' (inside 'Root Heading' section)",
+		},
+		{
+			name: "map: concat duplicated info",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Files").Merge(MergeConcat, "\n\n")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				~~~go :main.go
+				package main
+				~~~
+
+				~~~go :main.go
+				func main() {}
+				~~~
+				`),
+			},
+			want: &Doc{
+				Files: map[string]string{
+					"main.go": "package main\n\nfunc main() {}",
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFenceInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want fenceInfo
+	}{
+		{name: "empty", src: "", want: fenceInfo{}},
+		{name: "language only", src: "sql", want: fenceInfo{lang: "sql"}},
+		{name: "colon with space", src: "csv :users", want: fenceInfo{lang: "csv", info: "users"}},
+		{name: "colon without space", src: "go:main.go", want: fenceInfo{lang: "go", info: "main.go"}},
+		{
+			name: "attributes",
+			src:  `go title="main.go" {3-5} #example .hidden .wide linenums`,
+			want: fenceInfo{lang: "go", info: "main.go", attrs: map[string]string{
+				"title":     "main.go",
+				"highlight": "3-5",
+				"id":        "example",
+				"class":     "hidden wide",
+				"linenums":  "true",
+			}},
+		},
+		{
+			name: "pandoc style",
+			src:  `{.python #example startFrom=10}`,
+			want: fenceInfo{lang: "python", attrs: map[string]string{
+				"class":     "python",
+				"id":        "example",
+				"startFrom": "10",
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseFenceInfo([]byte(tc.src)))
+		})
+	}
+}
+
+func TestCodeFence_Attr(t *testing.T) {
+	type Doc struct {
+		Code      string
+		FileName  string
+		ID        string
+		Highlight []int
+		Snippets  []Snippet
+	}
+
+	src := TrimIndent(t, `
+	# Root Heading
+
+	~~~go title="main.go" {1,3-4} #example
+	package main
+	~~~
+	`)
+
+	t.Run("bind attributes", func(t *testing.T) {
+		jig := NewDocJig[Doc]()
+		jig.Root().CodeFence("Code", "go").Attr("title", "FileName").Attr("id", "ID").Attr("highlight", "Highlight")
+		got, err := jig.ParseString(src)
+		assert.NoError(t, err)
+		assert.Equal(t, &Doc{
+			Code:      "package main",
+			FileName:  "main.go",
+			ID:        "example",
+			Highlight: []int{1, 3, 4},
+		}, got)
+	})
+
+	t.Run("snippet keeps attributes", func(t *testing.T) {
+		jig := NewDocJig[Doc]()
+		jig.Root().CodeFence("Snippets")
+		got, err := jig.ParseString(src)
+		assert.NoError(t, err)
+		assert.Equal(t, []Snippet{{
+			Code:  "package main",
+			Lang:  "go",
+			Info:  "main.go",
+			Attrs: map[string]string{"title": "main.go", "highlight": "1,3-4", "id": "example"},
+		}}, got.Snippets)
+	})
+
+	t.Run("invalid line range", func(t *testing.T) {
+		jig := NewDocJig[Doc]()
+		jig.Root().CodeFence("Code").Attr("highlight", "Highlight")
+		_, err := jig.ParseString(TrimIndent(t, `
+		# Root Heading
+
+		~~~go {5-3}
+		package main
+		~~~
+		`))
+		assert.EqualError(t, err, "invalid line range '5-3'")
+	})
+
+	t.Run("struct tag", func(t *testing.T) {
+		type TagDoc struct {
+			Code      string `mdd:"fence=go,attr=title:FileName|highlight:Highlight"`
+			FileName  string
+			Highlight []int
+		}
+		jig := NewDocJigFromTags[TagDoc]()
+		got, err := jig.ParseString(src)
+		assert.NoError(t, err)
+		assert.Equal(t, &TagDoc{Code: "package main", FileName: "main.go", Highlight: []int{1, 3, 4}}, got)
+	})
+}
+
 func TestCodeFence_Generate(t *testing.T) {
 	type Doc struct {
 		Name string
@@ -269,3 +526,94 @@ func TestCodeFence_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestCodeFence_Whitespace(t *testing.T) {
+	type Doc struct {
+		Code     string
+		Indented string
+	}
+
+	src := "# Doc\n\n    indented block\n\n```txt\n\n    line1\n      line2\n\n```\n"
+
+	tests := []struct {
+		name   string
+		create func(jig *DocJig[Doc])
+		src    string
+		want   *Doc
+	}{
+		{
+			name: "default",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code").Merge(MergeLast)
+			},
+			src:  src,
+			want: &Doc{Code: "    line1\n      line2"},
+		},
+		{
+			name: "ignore indented",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code").IgnoreIndented()
+				jig.Root().CodeFence("Indented")
+			},
+			src:  src,
+			want: &Doc{Code: "    line1\n      line2", Indented: "indented block"},
+		},
+		{
+			name: "keep whitespace",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code", "txt").KeepWhitespace()
+			},
+			src:  src,
+			want: &Doc{Code: "\n    line1\n      line2\n\n"},
+		},
+		{
+			name: "dedent",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code", "txt").Dedent()
+			},
+			src:  src,
+			want: &Doc{Code: "line1\n  line2"},
+		},
+		{
+			name: "CRLF document",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code", "txt").KeepWhitespace()
+			},
+			src:  "# Doc\r\n\r\n```txt\r\na\r\nb\r\n```\r\n",
+			want: &Doc{Code: "a\r\nb\r\n"},
+		},
+		{
+			name: "mixed newlines",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code", "txt").KeepWhitespace()
+			},
+			src:  "# Doc\r\n\r\n  ```txt\r\n  a\n  b\r\n\n  ```\n",
+			want: &Doc{Code: "  a\n  b\r\n\n"},
+		},
+		{
+			name: "mixed newlines (indented)",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code").KeepWhitespace()
+			},
+			src:  "# Doc\r\n\r\n    a\n    b\r\n\tc\r\n",
+			want: &Doc{Code: "a\nb\r\nc\r\n"},
+		},
+		{
+			name: "normalize newline",
+			create: func(jig *DocJig[Doc]) {
+				jig.Root().CodeFence("Code", "txt").KeepWhitespace().NormalizeNewline()
+			},
+			src:  "# Doc\r\n\r\n```txt\r\na\r\nb\r\n```\r\n",
+			want: &Doc{Code: "a\nb\n"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := NewDocJig[Doc]()
+			tc.create(jig)
+			got, err := jig.ParseString(tc.src)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package mdd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dataFormat is the decoder of data code fence
+type dataFormat int
+
+const (
+	dataNone dataFormat = iota
+	dataCSV
+	dataTSV
+	dataJSON
+	dataYAML
+)
+
+func (f dataFormat) String() string {
+	switch f {
+	case dataCSV:
+		return "csv"
+	case dataTSV:
+		return "tsv"
+	case dataJSON:
+		return "json"
+	case dataYAML:
+		return "yaml"
+	}
+	return ""
+}
+
+// CSV decodes CSV code fences into the field (slice of struct or map, or map keyed by a column)
+//
+// The first line is the header. Returned [Table] maps columns to row struct fields with
+// the same rules as markdown tables (Field, Required, aliases and so on).
+// If no language is specified for this code fence, it matches "csv" fences.
+//
+//	users := root.CodeFence("Users").CSV()
+//	users.Field("Name").Required()
+func (cf *CodeFence[T]) CSV() *Table[T] {
+	return cf.dataTable(dataCSV)
+}
+
+// TSV is same as [CodeFence.CSV] but cells are separated by tab. It matches "tsv" fences by default.
+func (cf *CodeFence[T]) TSV() *Table[T] {
+	return cf.dataTable(dataTSV)
+}
+
+// JSON unmarshals JSON code fences into the field by encoding/json.
+// It matches "json" fences by default.
+func (cf *CodeFence[T]) JSON() *CodeFence[T] {
+	cf.setData(dataJSON)
+	return cf
+}
+
+// YAML unmarshals YAML code fences into the field by gopkg.in/yaml.v3.
+// It matches "yaml" and "yml" fences by default.
+func (cf *CodeFence[T]) YAML() *CodeFence[T] {
+	cf.setData(dataYAML)
+	return cf
+}
+
+func (cf *CodeFence[T]) setData(format dataFormat) {
+	cf.j.invalidate()
+	cf.data = format
+	if len(cf.targetLanguages) == 0 {
+		cf.defaultLanguages = true
+		cf.targetLanguages = []string{format.String()}
+		if format == dataYAML {
+			cf.targetLanguages = append(cf.targetLanguages, "yml")
+		}
+	}
+}
+
+func (cf *CodeFence[T]) dataTable(format dataFormat) *Table[T] {
+	cf.setData(format)
+	if cf.table == nil {
+		cf.table = &Table[T]{
+			j:         cf.j,
+			fieldName: cf.fieldName,
+		}
+	}
+	return cf.table
+}
+
+// decode stores content of data code fence into target
+func (cf *CodeFence[T]) decode(target reflect.Value, code string, fi fenceInfo, label string, doc *T) error {
+	if cf.data == dataCSV || cf.data == dataTSV {
+		r := csv.NewReader(strings.NewReader(code))
+		if cf.data == dataTSV {
+			r.Comma = '\t'
+			r.LazyQuotes = true
+		}
+		records, err := r.ReadAll()
+		if err != nil {
+			return cf.decodeError(err, code, fi, label)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		for _, record := range records {
+			for i, c := range record {
+				record[i] = strings.TrimSpace(c)
+			}
+		}
+		err = cf.table.assignCells(target, records[0], records[1:], label, doc)
+		if err != nil {
+			return fmt.Errorf("%s code fence%s: %w", cf.data, atLine(fi.line), err)
+		}
+		return nil
+	}
+	field := cf.ref.field(target)
+	if !field.IsValid() {
+		return fmt.Errorf("%s doesn't have field '%s' for code fence (inside '%s' section)", target.Type(), cf.fieldName, label)
+	}
+	if !field.IsZero() {
+		switch cf.merge.policy {
+		case MergeFirst:
+			return nil
+		case MergeLast:
+			field.Set(reflect.Zero(field.Type()))
+		case MergeError:
+			return fmt.Errorf("field '%s' for code fence is already filled (inside '%s' section)", cf.fieldName, label)
+		}
+	}
+	var err error
+	if cf.data == dataJSON {
+		err = json.Unmarshal([]byte(code), field.Addr().Interface())
+	} else {
+		err = yaml.Unmarshal([]byte(code), field.Addr().Interface())
+	}
+	if err != nil {
+		return cf.decodeError(err, code, fi, label)
+	}
+	return nil
+}
+
+// decodeError converts line number of decoder error into line number of the markdown file
+func (cf *CodeFence[T]) decodeError(err error, code string, fi fenceInfo, label string) error {
+	return fmt.Errorf("can't decode %s code fence%s (inside '%s' section): %w", cf.data, atLine(fi.line), label, newFenceError(err, code).locate(fi))
+}
+
+func atLine(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" at line %d", line)
+}
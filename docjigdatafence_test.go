@@ -0,0 +1,247 @@
+package mdd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataFence(t *testing.T) {
+	type User struct {
+		Name string
+		Age  int
+	}
+
+	type Config struct {
+		Host string `json:"host" yaml:"host"`
+		Port int    `json:"port" yaml:"port"`
+	}
+
+	type Doc struct {
+		Users  []User
+		Config Config
+		Any    map[string]any
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "csv",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					users := jig.Root().CodeFence("Users").CSV()
+					users.Field("Name").Required()
+					users.Field("Age")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Users
+
+				~~~csv :users
+				Name, Age
+				Alice, 20
+				"Bob, Jr.", 30
+				~~~
+				`),
+			},
+			want: &Doc{
+				Users: []User{{Name: "Alice", Age: 20}, {Name: "Bob, Jr.", Age: 30}},
+			},
+		},
+		{
+			name: "tsv with alias",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Alias("Name", "User Name")
+					users := jig.Root().CodeFence("Users").TSV()
+					users.Field("Name")
+					users.Field("Age")
+					return jig
+				},
+				src: "# Users\n\n```tsv\nUser Name\tAge\nAlice\t20\n```\n",
+			},
+			want: &Doc{
+				Users: []User{{Name: "Alice", Age: 20}},
+			},
+		},
+		{
+			name: "json and yaml",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Config").JSON()
+					jig.Root().CodeFence("Any").YAML()
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Config
+
+				~~~json
+				{"host": "localhost", "port": 8080}
+				~~~
+
+				~~~yaml
+				debug: true
+				~~~
+				`),
+			},
+			want: &Doc{
+				Config: Config{Host: "localhost", Port: 8080},
+				Any:    map[string]any{"debug": true},
+			},
+		},
+		{
+			name: "csv: required column",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					users := jig.Root().CodeFence("Users").CSV()
+					users.Field("Name").Required()
+					users.Field("Age")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Users
+
+				~~~csv
+				Age
+				20
+				~~~
+				`),
+			},
+			wantErr: "csv code fence at line 3: required column(Name) are missing (inside 'Users' section)",
+		},
+		{
+			name: "csv: syntax error",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					users := jig.Root().CodeFence("Users").CSV()
+					users.Field("Name")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Users
+
+				~~~csv
+				Name, Age
+				Alice
+				~~~
+				`),
+			},
+			wantErr: "can't decode csv code fence at line 3 (inside 'Users' section): line 5, column 1: wrong number of fields",
+		},
+		{
+			name: "json: syntax error",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Config").JSON()
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Config
+
+				~~~sql
+				select 1;
+				~~~
+
+				~~~json
+				{
+				  "host": "localhost",
+				  "port": "8080"
+				}
+				~~~
+				`),
+			},
+			wantErr: "can't decode json code fence at line 7 (inside 'Config' section): line 10: json: cannot unmarshal string into Go struct field Config.port of type int",
+		},
+		{
+			name: "yaml: syntax error",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Config").YAML()
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Config
+
+				~~~yaml
+				host: localhost
+				port: [
+				~~~
+				`),
+			},
+			wantErr: "can't decode yaml code fence at line 3 (inside 'Config' section): line 5: yaml: did not find expected node content",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDataFence_Generate(t *testing.T) {
+	type User struct {
+		Name string
+		Age  int
+	}
+
+	type Doc struct {
+		Users []User
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Alias("Name").Lang("ja", "名前")
+	users := jig.Root().CodeFence("Users").CSV()
+	users.Field("Name")
+	users.Field("Age")
+
+	var b bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&b, GenerateOption{Language: "ja"}))
+	assert.Equal(t, "# [Title]\n\n```csv\n名前,Age\n```", strings.TrimRight(b.String(), "\n"))
+}
+
+func TestDataFence_Tags(t *testing.T) {
+	type User struct {
+		Name string `mdd:"column,required"`
+		Age  int    `mdd:"column=Years"`
+	}
+
+	type Doc struct {
+		Users []User `mdd:"fence,format=csv"`
+	}
+
+	jig := NewDocJigFromTags[Doc]()
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Users
+
+	~~~csv
+	Name,Years
+	Alice,20
+	~~~
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{Users: []User{{Name: "Alice", Age: 20}}}, got)
+}
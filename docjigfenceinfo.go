@@ -0,0 +1,283 @@
+package mdd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fenceInfo is parsed info string of code fence
+type fenceInfo struct {
+	lang  string
+	info  string
+	attrs map[string]string
+	line  int // line number of the opening fence (0 if unknown)
+
+	offset  int    // blank lines trimmed from the head of the body
+	include string // included file name (empty if the body is in the document)
+	lines   []int  // line numbers in the included file of each line of the body (nil if unknown)
+}
+
+// parseFenceInfo parses code block first line
+//
+// It supports the classic ":name" suffix:
+//
+//	```csv :users
+//	```go:main.go
+//
+// and pandoc/GFM style attributes. "{3-5}" is stored as "highlight", "#example" as "id"
+// and ".hidden" as "class" (space separated). Without ":name", title attribute is used as info:
+//
+//	```go title="main.go" {3-5} #example .hidden
+//	```{.python #example}
+func parseFenceInfo(src []byte) fenceInfo {
+	var r fenceInfo
+	s := strings.TrimSpace(string(src))
+	if s == "" {
+		return r
+	}
+	lang, rest := s, ""
+	if i := strings.IndexFunc(s, unicode.IsSpace); i != -1 {
+		lang, rest = s[:i], strings.TrimSpace(s[i:])
+	}
+	if strings.Contains(lang, ":") || strings.HasPrefix(rest, ":") {
+		mode, name, _ := strings.Cut(s, ":")
+		r.lang = strings.TrimSpace(mode)
+		r.info = strings.TrimSpace(name)
+		return r
+	}
+	if strings.HasPrefix(lang, "{") {
+		lang, rest = "", s
+	}
+	r.lang = lang
+	r.attrs = make(map[string]string)
+	parseFenceAttrs(rest, r.attrs)
+	if len(r.attrs) == 0 {
+		r.attrs = nil
+	}
+	if r.lang == "" && r.attrs["class"] != "" {
+		// pandoc style: ```{.python}
+		r.lang, _, _ = strings.Cut(r.attrs["class"], " ")
+	}
+	r.info = r.attrs["title"]
+	return r
+}
+
+var lineRangesPattern = regexp.MustCompile(`^[\d\s,-]+$`)
+
+func parseFenceAttrs(s string, attrs map[string]string) {
+	appendAttr := func(key, value, sep string) {
+		if prev, ok := attrs[key]; ok && prev != "" {
+			value = prev + sep + value
+		}
+		attrs[key] = value
+	}
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return
+		}
+		switch s[0] {
+		case '{':
+			body, rest, _ := strings.Cut(s[1:], "}")
+			s = rest
+			if lineRangesPattern.MatchString(body) {
+				appendAttr("highlight", strings.TrimSpace(body), ",")
+			} else {
+				parseFenceAttrs(body, attrs)
+			}
+			continue
+		case '#':
+			var word string
+			word, s = cutWord(s[1:])
+			attrs["id"] = word
+			continue
+		case '.':
+			var word string
+			word, s = cutWord(s[1:])
+			appendAttr("class", word, " ")
+			continue
+		}
+		i := strings.IndexFunc(s, func(r rune) bool { return r == '=' || unicode.IsSpace(r) })
+		if i == -1 || s[i] != '=' {
+			var word string
+			word, s = cutWord(s)
+			attrs[word] = "true"
+			continue
+		}
+		key := s[:i]
+		s = s[i+1:]
+		var value string
+		if s != "" && (s[0] == '"' || s[0] == '\'') {
+			quote := s[:1]
+			value, s, _ = strings.Cut(s[1:], quote)
+		} else {
+			value, s = cutWord(s)
+		}
+		attrs[key] = value
+	}
+}
+
+// cutWord splits s at the first space or brace
+func cutWord(s string) (word, rest string) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r == '{' || r == '}' || unicode.IsSpace(r) })
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// parseLineRanges parses "3-5,7" into [3 4 5 7]
+func parseLineRanges(s string) ([]int, error) {
+	var result []int
+	for _, r := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		from, to, isRange := strings.Cut(r, "-")
+		f, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line range '%s'", r)
+		}
+		t := f
+		if isRange {
+			t, err = strconv.Atoi(to)
+			if err != nil || t < f {
+				return nil, fmt.Errorf("invalid line range '%s'", r)
+			}
+		}
+		for l := f; l <= t; l++ {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// lineRangesSetter returns setter for integer slice type t
+func lineRangesSetter(t reflect.Type) stringSetter {
+	return func(field reflect.Value, value string) error {
+		lines, err := parseLineRanges(value)
+		if err != nil {
+			return err
+		}
+		v := reflect.MakeSlice(t, len(lines), len(lines))
+		for i, l := range lines {
+			v.Index(i).Set(reflect.ValueOf(l).Convert(t.Elem()))
+		}
+		field.Set(v)
+		return nil
+	}
+}
+
+// fenceLocator finds line numbers of fenced code blocks in markdown source
+//
+// blackfriday doesn't keep source positions, so fences are scanned separately and
+// matched with code block nodes by content in document order.
+type fenceLocator struct {
+	fences []locatedFence
+	next   int
+}
+
+type locatedFence struct {
+	line int    // line number of the opening fence
+	end  int    // line number of the closing fence (0 if not closed)
+	info string // text after the opening fence
+	body string
+}
+
+func newFenceLocator(src string) *fenceLocator {
+	l := &fenceLocator{}
+	var marker string
+	var current *locatedFence
+	var body []string
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			if current != nil {
+				body = append(body, line)
+			}
+			continue
+		}
+		if current == nil {
+			if m := fenceMarker(trimmed); m != "" {
+				marker = m
+				current = &locatedFence{line: i + 1, info: strings.TrimSpace(trimmed[len(m):])}
+				body = nil
+			}
+			continue
+		}
+		if m := strings.TrimSpace(trimmed); strings.HasPrefix(m, marker) && strings.Trim(m, marker[:1]) == "" {
+			current.body = strings.Join(body, "\n")
+			current.end = i + 1
+			l.fences = append(l.fences, *current)
+			current = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	if current != nil {
+		current.body = strings.Join(body, "\n")
+		l.fences = append(l.fences, *current)
+	}
+	return l
+}
+
+// restoreCR puts CR back to each line of code block literal parsed from CRLF normalized source.
+// start is the index of srcLines (split by LF) where the body starts.
+func restoreCR(literal string, srcLines []string, start int) string {
+	var b strings.Builder
+	for i, line := range strings.SplitAfter(literal, "\n") {
+		if n := start + i; strings.HasSuffix(line, "\n") && n < len(srcLines) && strings.HasSuffix(srcLines[n], "\r") {
+			line = line[:len(line)-1] + "\r\n"
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// findIndentedBlock returns the index of srcLines (split by LF) where the indented code block starts (-1 if not found)
+func findIndentedBlock(literal string, srcLines []string, from int) int {
+	lines := strings.Split(strings.TrimSuffix(literal, "\n"), "\n")
+	unindent := func(line string) string {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "\t") {
+			return line[1:]
+		}
+		for i := 0; i < 4 && strings.HasPrefix(line, " "); i++ {
+			line = line[1:]
+		}
+		return line
+	}
+search:
+	for start := from; start+len(lines) <= len(srcLines); start++ {
+		for i, l := range lines {
+			if unindent(srcLines[start+i]) != l {
+				continue search
+			}
+		}
+		return start
+	}
+	return -1
+}
+
+// fenceMarker returns opening fence (``` or ~~~ and more) of line
+func fenceMarker(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, line[:1]))
+	return line[:n]
+}
+
+// find returns line number of the fence that has the literal (0 if not found)
+func (l *fenceLocator) find(literal []byte) int {
+	code := strings.Trim(string(literal), "\n")
+	for i := l.next; i < len(l.fences); i++ {
+		if strings.Trim(l.fences[i].body, "\n") == code {
+			l.next = i + 1
+			return l.fences[i].line
+		}
+	}
+	return 0
+}
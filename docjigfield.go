@@ -1,12 +1,26 @@
 package mdd
 
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
 type StructField[T any] struct {
+	j         *DocJig[T]
 	fieldName string
 	key       string
 	origKey   string
 	required  bool
 	convert   func(value string, t *T) (any, error)
 	samples   []any
+	bools     *boolVocabulary
+	split     *splitRule
+
+	// compiled plan
+	ref       fieldRef
+	blankBool bool
 }
 
 func (s *StructField[T]) Alias(alias ...string) *StructField[T] {
@@ -18,11 +32,120 @@ func (s *StructField[T]) Required() *StructField[T] {
 	return s
 }
 
+// As sets converter of cells. It is called with blank for rows of tables that don't have the column.
 func (s *StructField[T]) As(convert func(value string, t *T) (any, error)) *StructField[T] {
 	s.convert = convert
 	return s
 }
 
+// Bool returns [BoolWords] only for this field. It replaces jig level words.
+func (s *StructField[T]) Bool() *BoolWords {
+	if s.bools == nil {
+		s.bools = newBoolVocabulary(s.j.invalidate)
+	}
+	return &BoolWords{
+		vocab: s.bools,
+		lang:  s.j.DefaultLang,
+	}
+}
+
+// Split fills slice field by splitting cell text
+//
+// sep is literal separator like ",". "<br>" and "\n" split at line breaks in the cell.
+// Each element is converted into the element type of the slice ([]int, []time.Time...) and
+// trimmed. Empty elements are skipped. Use [StructField.Trim] and [StructField.KeepSpace] to modify trimming.
+func (s *StructField[T]) Split(sep string) *StructField[T] {
+	s.j.invalidate()
+	if isLineBreakTag([]byte(sep)) {
+		sep = "\n"
+	}
+	s.splitRule().sep = sep
+	s.split.re = nil
+	return s
+}
+
+// SplitRegexp is a variation of [StructField.Split] that uses regular expression as separator
+func (s *StructField[T]) SplitRegexp(re *regexp.Regexp) *StructField[T] {
+	s.j.invalidate()
+	s.splitRule().re = re
+	s.split.sep = ""
+	return s
+}
+
+// Trim specifies characters to trim from each element in addition to white spaces (e.g. "`'\"")
+func (s *StructField[T]) Trim(cutset string) *StructField[T] {
+	s.j.invalidate()
+	s.splitRule().cutset = cutset
+	return s
+}
+
+// KeepSpace disables trimming and keeps empty elements
+func (s *StructField[T]) KeepSpace() *StructField[T] {
+	s.j.invalidate()
+	s.splitRule().keepSpace = true
+	return s
+}
+
+func (s *StructField[T]) splitRule() *splitRule {
+	if s.split == nil {
+		s.split = &splitRule{sep: ","}
+	}
+	return s.split
+}
+
 func (s *StructField[T]) Samples(samples ...any) {
 	s.samples = samples
 }
+
+// splitRule is a rule to split list-valued cell
+type splitRule struct {
+	sep       string
+	re        *regexp.Regexp
+	cutset    string
+	keepSpace bool
+}
+
+func (r *splitRule) elements(value string) []string {
+	var items []string
+	if r.re != nil {
+		items = r.re.Split(value, -1)
+	} else {
+		items = strings.Split(value, r.sep)
+	}
+	if r.keepSpace {
+		return items
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.Trim(strings.TrimSpace(item), r.cutset)
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// setter returns setter that splits value and stores elements into slice field of type t.
+func (r *splitRule) setter(t reflect.Type, elemSetter func(reflect.Type) stringSetter) stringSetter {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() != reflect.Slice {
+		return func(field reflect.Value, value string) error {
+			return fmt.Errorf("field to split should be slice, but %s", t)
+		}
+	}
+	set := elemSetter(t.Elem())
+	return func(field reflect.Value, value string) error {
+		items := r.elements(value)
+		v := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := set(v.Index(i), item); err != nil {
+				return fmt.Errorf("element %d: %w", i+1, err)
+			}
+		}
+		field.Set(v)
+		return nil
+	}
+}
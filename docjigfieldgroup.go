@@ -0,0 +1,217 @@
+package mdd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldGroup maps several boolean columns into one field
+//
+// It is created by [Table.FieldGroup]. Each cell is parsed by boolean words (see [BoolWords])
+// and marked columns are stored into the field. The field type should be one of:
+//
+//   - []string (or slice of string kind type): names of marked columns in definition order
+//   - map[string]bool: columns that exist in the table with their values
+//   - integer kind type (bit flags): 1<<i is set when i-th column is marked
+type FieldGroup[T any] struct {
+	j         *DocJig[T]
+	fieldName string
+	columns   []string
+	samples   []any
+	bools     *boolVocabulary
+
+	// compiled plan
+	ref      fieldRef
+	keys     [][]string // lower cased column name and aliases
+	boolMap  map[string]bool
+	fieldErr string
+}
+
+// FieldGroup maps columns into one field. See [FieldGroup] for supported field types.
+//
+//	table.FieldGroup("Ops", "C", "R", "U", "D")
+//
+// Calling it again with the same field and columns returns the existing definition to refine it.
+func (t *Table[T]) FieldGroup(fieldName string, columns ...string) *FieldGroup[T] {
+	for _, item := range t.items {
+		if g := item.group; g != nil && g.fieldName == fieldName && sameStrings(g.columns, columns) {
+			return g // refine existing definition
+		}
+	}
+	t.j.invalidate()
+	g := &FieldGroup[T]{
+		j:         t.j,
+		fieldName: fieldName,
+		columns:   columns,
+	}
+	t.items = append(t.items, tableItem[T]{group: g})
+	return g
+}
+
+// Bool returns [BoolWords] only for this group. It replaces jig level words.
+func (g *FieldGroup[T]) Bool() *BoolWords {
+	if g.bools == nil {
+		g.bools = newBoolVocabulary(g.j.invalidate)
+	}
+	return &BoolWords{
+		vocab: g.bools,
+		lang:  g.j.DefaultLang,
+	}
+}
+
+// Samples specifies sample values for template. Values are same type as the field
+// ([]string, map[string]bool or integer).
+func (g *FieldGroup[T]) Samples(samples ...any) {
+	g.samples = samples
+}
+
+func (g *FieldGroup[T]) compile(rowType reflect.Type) {
+	g.ref = resolveField(rowType, g.fieldName)
+	g.boolMap = g.j.boolMap
+	if g.bools != nil {
+		g.boolMap = g.bools.table(minimumBoolWords)
+	}
+	g.keys = make([][]string, len(g.columns))
+	for i, c := range g.columns {
+		lc := strings.ToLower(c)
+		g.keys[i] = []string{lc}
+		for _, a := range g.j.aliases[lc] {
+			g.keys[i] = append(g.keys[i], a.lowLabel)
+		}
+	}
+	g.fieldErr = ""
+	ft := g.ref.typ(rowType)
+	if ft == nil {
+		return
+	}
+	switch {
+	case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.String:
+	case ft.Kind() == reflect.Map && ft.Key().Kind() == reflect.String && ft.Elem().Kind() == reflect.Bool:
+	case isIntKind(ft.Kind()):
+		if len(g.columns) > ft.Bits() {
+			g.fieldErr = fmt.Sprintf("%s doesn't have enough bits for %d columns", ft, len(g.columns))
+		}
+	default:
+		g.fieldErr = fmt.Sprintf("field group '%s' should be []string, map[string]bool or integer, but %s", g.fieldName, ft)
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// columnIndexes returns header index of each column (-1 if missing)
+func (g *FieldGroup[T]) columnIndexes(headers []string) []int {
+	result := make([]int, len(g.columns))
+	for i, keys := range g.keys {
+		result[i] = -1
+	found:
+		for c, h := range headers {
+			lh := strings.ToLower(h)
+			for _, k := range keys {
+				if k == lh {
+					result[i] = c
+					break found
+				}
+			}
+		}
+	}
+	return result
+}
+
+// assign stores marked columns of the row into field
+func (g *FieldGroup[T]) assign(field reflect.Value, columns []int, row []string) (int, error) {
+	var names []string
+	var flags uint64
+	marks := make(map[string]bool)
+	for i, c := range columns {
+		if c == -1 {
+			continue
+		}
+		b, err := parseBool(g.boolMap, row[c])
+		if err != nil {
+			return c, err
+		}
+		marks[g.columns[i]] = b
+		if b {
+			names = append(names, g.columns[i])
+			flags |= 1 << i
+		}
+	}
+	switch field.Kind() {
+	case reflect.Slice:
+		v := reflect.MakeSlice(field.Type(), len(names), len(names))
+		for i, n := range names {
+			v.Index(i).SetString(n)
+		}
+		field.Set(v)
+	case reflect.Map:
+		v := reflect.MakeMapWithSize(field.Type(), len(marks))
+		for k, b := range marks {
+			v.SetMapIndex(reflect.ValueOf(k).Convert(field.Type().Key()), reflect.ValueOf(b).Convert(field.Type().Elem()))
+		}
+		field.Set(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(flags)
+	default:
+		field.SetInt(int64(flags))
+	}
+	return -1, nil
+}
+
+// sampleMarks converts sample value into column marks for template
+func (g *FieldGroup[T]) sampleMarks(sample any) []bool {
+	result := make([]bool, len(g.columns))
+	v := reflect.ValueOf(sample)
+	switch {
+	case v.Kind() == reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			name := fmt.Sprint(v.Index(i).Interface()) // elements can be any (samples in schema)
+			for ci, c := range g.columns {
+				if strings.EqualFold(c, name) {
+					result[ci] = true
+				}
+			}
+		}
+	case v.Kind() == reflect.Map:
+		for ci, c := range g.columns {
+			if b := v.MapIndex(reflect.ValueOf(c).Convert(v.Type().Key())); b.IsValid() {
+				if b.Kind() == reflect.Interface {
+					b = b.Elem()
+				}
+				result[ci] = b.Kind() == reflect.Bool && b.Bool()
+			}
+		}
+	case isIntKind(v.Kind()):
+		var flags uint64
+		if v.CanInt() {
+			flags = uint64(v.Int())
+		} else {
+			flags = v.Uint()
+		}
+		for ci := range g.columns {
+			result[ci] = flags&(1<<ci) != 0
+		}
+	}
+	return result
+}
+
+// sampleWord returns the word for template
+func (g *FieldGroup[T]) sampleWord(value bool, lang string) string {
+	if w, ok := g.bools.word(value, lang); ok {
+		return w
+	}
+	if w, ok := g.j.bools.word(value, lang); ok {
+		return w
+	}
+	if value {
+		return "X"
+	}
+	return ""
+}
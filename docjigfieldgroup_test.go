@@ -0,0 +1,180 @@
+package mdd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testOp uint8
+
+const (
+	testOpCreate testOp = 1 << iota
+	testOpRead
+	testOpUpdate
+	testOpDelete
+)
+
+func TestFieldGroup(t *testing.T) {
+	type CRUD struct {
+		Table string
+		Ops   []string
+		OpMap map[string]bool
+		Flags testOp
+		Bad   string
+	}
+
+	type Doc struct {
+		CRUD []CRUD
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "slice of names",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("CRUD")
+					table.Field("Table")
+					table.FieldGroup("Ops", "C", "R", "U", "D")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| Table  | C | R | U | D |
+				|--------|---|---|---|---|
+				| users  |   | X | X |   |
+				| groups |   |   |   |   |
+				`),
+			},
+			want: &Doc{
+				CRUD: []CRUD{
+					{Table: "users", Ops: []string{"R", "U"}},
+					{Table: "groups", Ops: []string{}},
+				},
+			},
+		},
+		{
+			name: "map and bit flags",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Alias("C", "Create")
+					table := jig.Root().Table("CRUD")
+					table.Field("Table")
+					table.FieldGroup("OpMap", "C", "R", "U", "D")
+					table.FieldGroup("Flags", "C", "R", "U", "D")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| Table  | Create | R | D |
+				|--------|--------|---|---|
+				| users  | ✓      | ✓ |   |
+				`),
+			},
+			want: &Doc{
+				CRUD: []CRUD{
+					{
+						Table: "users",
+						OpMap: map[string]bool{"C": true, "R": true, "D": false},
+						Flags: testOpCreate | testOpRead,
+					},
+				},
+			},
+		},
+		{
+			name: "unknown mark",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("CRUD")
+					table.Field("Table")
+					table.FieldGroup("Ops", "C", "R", "U", "D")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| Table  | C | R |
+				|--------|---|---|
+				| users  | X | ? |
+				`),
+			},
+			wantErr: "can't convert value '?' at row 1, column 'R' of table 'CRUD' (inside 'Query' section): unknown boolean value '?'",
+		},
+		{
+			name: "invalid field type",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("CRUD")
+					table.FieldGroup("Bad", "C", "R", "U", "D")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Query
+
+				| C | R |
+				|---|---|
+				| X |   |
+				`),
+			},
+			wantErr: "field group 'Bad' should be []string, map[string]bool or integer, but string (inside 'Query' section)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFieldGroup_Generate(t *testing.T) {
+	type CRUD struct {
+		Table       string
+		Ops         []string
+		Description string
+	}
+
+	type Doc struct {
+		CRUD []CRUD
+	}
+
+	jig := NewDocJig[Doc]()
+	table := jig.Root().Table("CRUD")
+	table.Field("Table").Samples("users", "groups")
+	table.FieldGroup("Ops", "C", "R", "U", "D").Samples([]string{"R"}, []string{"C", "D"})
+	table.Field("Description")
+
+	var b bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&b))
+	assert.Equal(t, TrimIndent(t, `
+	# [Title]
+
+	| Table  | C | R | U | D | Description |
+	|--------|---|---|---|---|-------------|
+	| users  |   | X |   |   | ...         |
+	| groups | X |   |   | X | ...         |
+	`), strings.TrimRight(b.String(), "\n"))
+}
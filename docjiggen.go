@@ -0,0 +1,683 @@
+package mdd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GenerateGoOption is option for [Schema.GenerateGo]
+type GenerateGoOption struct {
+	Package  string // Package name (required)
+	TypeName string // Root document type name. Default is "Doc"
+	JigName  string // Variable name of DocJig. Default is TypeName + "Jig"
+	Tags     bool   // Use `mdd` struct tags and NewDocJigFromTags instead of builder methods if tags can express the schema
+}
+
+var goTypes = map[string]string{
+	"":       "string",
+	"string": "string",
+	"int":    "int",
+	"float":  "float64",
+	"bool":   "bool",
+}
+
+// GenerateGo generates Go source code that has types and DocJig definition of this schema
+//
+// It is for "schema first" workflow. Use it via mdd command from go:generate:
+//
+//	//go:generate go run github.com/shibukawa/mdd-go/cmd/mdd gen -s sql.yaml -t SQLDoc -o sqldoc_gen.go
+func (s *Schema) GenerateGo(w io.Writer, opt GenerateGoOption) error {
+	if opt.Package == "" {
+		return fmt.Errorf("package name is required")
+	}
+	if opt.TypeName == "" {
+		opt.TypeName = "Doc"
+	}
+	if opt.JigName == "" {
+		opt.JigName = opt.TypeName + "Jig"
+	}
+	// reports invalid definitions (merge policies, validators, regexps...) before generating code
+	if _, err := NewDocJigFromSchema(s); err != nil {
+		return err
+	}
+	g := &goGenerator{
+		opt: opt,
+	}
+	root := &goStruct{name: opt.TypeName}
+	g.structs = append(g.structs, root)
+	if err := g.addLayout(root, s.Root); err != nil {
+		return err
+	}
+	if len(s.Bool) > 0 || s.GuessLanguage {
+		// struct tags can't express jig level definitions
+		g.opt.Tags = false
+	}
+
+	opt = g.opt
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mdd gen; DO NOT EDIT.\n\npackage %s\n\n", opt.Package)
+	if g.regexp {
+		b.WriteString("import (\n\"regexp\"\n\n\"github.com/shibukawa/mdd-go\"\n)\n\n")
+	} else {
+		b.WriteString("import \"github.com/shibukawa/mdd-go\"\n\n")
+	}
+	for _, st := range g.structs {
+		fmt.Fprintf(&b, "type %s struct {\n", st.name)
+		for _, f := range st.fields {
+			if opt.Tags && len(f.tags) > 0 {
+				fmt.Fprintf(&b, "\t%s %s `mdd:%s`\n", f.name, f.typ, strconv.Quote(strings.Join(f.tags, ",")))
+			} else {
+				fmt.Fprintf(&b, "\t%s %s\n", f.name, f.typ)
+			}
+		}
+		b.WriteString("}\n\n")
+	}
+	if opt.Tags {
+		fmt.Fprintf(&b, "var %s = mdd.NewDocJigFromTags[%s]()\n\n", opt.JigName, opt.TypeName)
+	} else {
+		fmt.Fprintf(&b, "var %s = mdd.NewDocJig[%s]()\n\n", opt.JigName, opt.TypeName)
+	}
+	b.WriteString("func init() {\n")
+	if s.DefaultLang != "" {
+		fmt.Fprintf(&b, "%s.DefaultLang = %s\n", opt.JigName, strconv.Quote(s.DefaultLang))
+	}
+	for _, a := range s.Aliases {
+		fmt.Fprintf(&b, "%s.Alias(%s)", opt.JigName, quoteAll(append([]string{a.Label}, a.Aliases...)))
+		langs := make([]string, 0, len(a.Langs))
+		for lang := range a.Langs {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			fmt.Fprintf(&b, ".Lang(%s)", quoteAll(append([]string{lang}, a.Langs[lang]...)))
+		}
+		b.WriteString("\n")
+	}
+	g.writeBool(&b, opt.JigName, s.Bool)
+	if s.GuessLanguage {
+		fmt.Fprintf(&b, "%s.GuessLanguage()\n", opt.JigName)
+	}
+	var body bytes.Buffer
+	g.writeBuilder(&body, "root", s.Root, 1)
+	if body.Len() > 0 {
+		fmt.Fprintf(&b, "root := %s.Root()\n", opt.JigName)
+		b.Write(body.Bytes())
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated code is invalid: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type goField struct {
+	name string
+	typ  string
+	tags []string
+}
+
+type goStruct struct {
+	name   string
+	fields []*goField
+}
+
+func (st *goStruct) add(name, typ string, tags ...string) error {
+	if name == "" {
+		return nil
+	}
+	for _, f := range st.fields {
+		if f.name == name {
+			if f.typ != typ {
+				return fmt.Errorf("invalid schema: field '%s' is defined twice with different types (%s, %s)", name, f.typ, typ)
+			}
+			f.tags = append(f.tags, tags...)
+			return nil
+		}
+	}
+	st.fields = append(st.fields, &goField{name: name, typ: typ, tags: tags})
+	return nil
+}
+
+type goGenerator struct {
+	opt     GenerateGoOption
+	structs []*goStruct
+	regexp  bool // generated code uses regexp package
+}
+
+func goType(name string) (string, error) {
+	t, ok := goTypes[name]
+	if !ok {
+		return "", fmt.Errorf("invalid schema: unknown type '%s' (string, int, float and bool are available)", name)
+	}
+	return t, nil
+}
+
+// tagValue returns v as value of mdd tag. If struct tags can't express v (it has comma, backquote, separators
+// or spaces around it), it switches to builder methods.
+func (g *goGenerator) tagValue(v, separators string) string {
+	if strings.ContainsAny(v, ",`"+separators) || v != strings.TrimSpace(v) {
+		g.opt.Tags = false
+	}
+	return v
+}
+
+func (g *goGenerator) withPattern(key, pattern string) string {
+	if pattern == "" {
+		return key
+	}
+	return key + "=" + g.tagValue(pattern, "")
+}
+
+var goGroupTypes = map[string]string{
+	"":    "[]string",
+	"map": "map[string]bool",
+	"int": "int",
+}
+
+// goColumnType returns the type of table column or field group.
+// Definitions that struct tags can't express switch to builder methods.
+func (g *goGenerator) goColumnType(f SchemaField) (string, error) {
+	if _, err := schemaColumnType(f); err != nil {
+		return "", err
+	}
+	if len(f.Bool) > 0 {
+		g.opt.Tags = false
+	}
+	if f.Group != nil {
+		return goGroupTypes[f.Type], nil
+	}
+	t, err := goType(f.Type)
+	if err != nil {
+		return "", err
+	}
+	if f.Split != nil {
+		g.opt.Tags = false
+		if f.Split.Regexp != "" {
+			g.regexp = true
+		}
+		t = "[]" + t
+	}
+	return t, nil
+}
+
+// addTable adds the table field (not transposed) and its row struct
+func (g *goGenerator) addTable(st *goStruct, s SchemaTable, tags []string) error {
+	container := "[]"
+	if s.KeyBy != "" {
+		tags = append(tags, "key="+g.tagValue(s.KeyBy, ""))
+		container = "map[string]"
+	}
+	if s.Strict || s.Merge != nil {
+		// struct tags can't express them
+		g.opt.Tags = false
+	}
+	if s.AsMap {
+		return st.add(s.Field, container+"map[string]string", tags...)
+	}
+	row := &goStruct{name: st.name + s.Field}
+	g.structs = append(g.structs, row)
+	for _, f := range s.Fields {
+		t, err := g.goColumnType(f)
+		if err != nil {
+			return err
+		}
+		var tags []string
+		if f.Key != "" {
+			tags = append(tags, "column="+g.tagValue(f.Key, ""))
+		}
+		if f.Group != nil {
+			columns := make([]string, len(f.Group))
+			for i, c := range f.Group {
+				columns[i] = g.tagValue(c, "|")
+			}
+			tags = append(tags, "group="+strings.Join(columns, "|"))
+		}
+		if f.Required {
+			tags = append(tags, "required")
+		}
+		if err := row.add(f.Field, t, tags...); err != nil {
+			return err
+		}
+	}
+	if err := row.add(s.Extra, "map[string]string", "extra"); err != nil {
+		return err
+	}
+	return st.add(s.Field, container+row.name, tags...)
+}
+
+var goFenceTypes = map[string]string{
+	"":         "string",
+	"list":     "[]string",
+	"map":      "map[string]string",
+	"snippets": "[]mdd.Snippet",
+}
+
+func (g *goGenerator) addLayout(st *goStruct, s SchemaLayout) error {
+	if s.Label != "" {
+		tag := "label"
+		if s.Field == "" {
+			tag = g.withPattern("label", s.Pattern)
+		}
+		typ := "string"
+		if s.LabelMerge != nil {
+			// struct tags can't express merge policies
+			g.opt.Tags = false
+			if s.LabelMerge.appends() {
+				typ = "[]string"
+			}
+		}
+		if err := st.add(s.Label, typ, tag); err != nil {
+			return err
+		}
+	}
+	for _, o := range s.Options {
+		t, err := goType(o.Type)
+		if err != nil {
+			return err
+		}
+		if o.Merge != nil || len(o.Bool) > 0 {
+			g.opt.Tags = false
+		}
+		if o.Merge.appends() {
+			t = "[]" + t
+		}
+		if err := st.add(o.Field, t, g.withPattern("option", o.Pattern)); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.CodeFences {
+		langs := make([]string, len(c.Languages))
+		for i, l := range c.Languages {
+			langs[i] = g.tagValue(l, "|")
+		}
+		tags := []string{g.withPattern("fence", strings.Join(langs, "|"))}
+		if c.Language != "" {
+			tags = append(tags, "lang="+c.Language)
+		}
+		if c.Info != "" {
+			tags = append(tags, "info="+c.Info)
+		}
+		var attrs []string
+		for _, name := range sortedKeys(c.Attrs) {
+			attrs = append(attrs, g.tagValue(name, "|:")+":"+c.Attrs[name])
+		}
+		if len(attrs) > 0 {
+			tags = append(tags, "attr="+strings.Join(attrs, "|"))
+		}
+		switch c.Format {
+		case "csv", "tsv":
+			tags = append(tags, "format="+c.Format)
+			table := SchemaTable{Field: c.Field, AsMap: true}
+			if c.Table != nil {
+				table = *c.Table
+				table.Field = c.Field
+			}
+			if err := g.addTable(st, table, tags); err != nil {
+				return err
+			}
+		case "json", "yaml":
+			if err := st.add(c.Field, "any", append(tags, "format="+c.Format)...); err != nil {
+				return err
+			}
+		default:
+			if _, err := schemaFenceType(c); err != nil {
+				return err
+			}
+			typ := goFenceTypes[c.Type]
+			if c.Type == "" && c.Merge.appends() {
+				typ = "[]string"
+			}
+			if err := st.add(c.Field, typ, tags...); err != nil {
+				return err
+			}
+		}
+		if c.Merge != nil || len(c.Validate) > 0 || c.IgnoreIndented || c.KeepWhitespace || c.Dedent || c.NormalizeNewline {
+			// struct tags can't express them
+			g.opt.Tags = false
+		}
+		for _, name := range sortedKeys(c.Attrs) {
+			typ := "string"
+			if name == "highlight" {
+				typ = "[]int"
+			}
+			if err := st.add(c.Attrs[name], typ); err != nil {
+				return err
+			}
+		}
+		if err := st.add(c.Language, "string"); err != nil {
+			return err
+		}
+		if err := st.add(c.Info, "string"); err != nil {
+			return err
+		}
+	}
+	if s.Table != nil && s.Table.Transpose {
+		// struct tags can't express transposed table
+		g.opt.Tags = false
+		row := st
+		if s.Table.Field != "." {
+			row = &goStruct{name: st.name + s.Table.Field}
+			g.structs = append(g.structs, row)
+		}
+		for _, f := range s.Table.Fields {
+			t, err := g.goColumnType(f)
+			if err != nil {
+				return err
+			}
+			if err := row.add(f.Field, t); err != nil {
+				return err
+			}
+		}
+		if row != st {
+			if err := st.add(s.Table.Field, row.name); err != nil {
+				return err
+			}
+		}
+	} else if s.Table != nil {
+		var tags []string
+		if s.Field == "." {
+			if s.Repeat {
+				tags = append(tags, "children="+g.tagValue(s.Pattern, ""))
+			} else {
+				tags = append(tags, "child="+g.tagValue(s.Pattern, ""))
+			}
+		}
+		tags = append(tags, "table")
+		if err := g.addTable(st, *s.Table, tags); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.Children {
+		if c.Field == "." {
+			if c.Table == nil || c.Label != "" || len(c.Options) > 0 || len(c.CodeFences) > 0 || len(c.Children) > 0 {
+				// struct tags can't express "." child except table
+				g.opt.Tags = false
+			}
+			if err := g.addLayout(st, c); err != nil {
+				return err
+			}
+			continue
+		}
+		child := &goStruct{name: st.name + c.Field}
+		g.structs = append(g.structs, child)
+		if err := g.addLayout(child, c); err != nil {
+			return err
+		}
+		if c.Repeat {
+			if err := st.add(c.Field, "[]"+child.name, g.withPattern("children", c.Pattern)); err != nil {
+				return err
+			}
+		} else {
+			if err := st.add(c.Field, child.name, g.withPattern("child", c.Pattern)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeBuilder writes builder method calls for the layout stored in variable v.
+//
+// In tags mode, it writes only definitions that can't be expressed by tags (samples).
+func (g *goGenerator) writeBuilder(b *bytes.Buffer, v string, s SchemaLayout, level int) {
+	tags := g.opt.Tags
+	if !tags {
+		if level == 1 && s.Pattern != "" {
+			fmt.Fprintf(b, "%s.Label(%s)\n", v, quoteAll([]string{s.Label, s.Pattern}))
+		} else if s.Label != "" {
+			fmt.Fprintf(b, "%s.Label(%s)\n", v, strconv.Quote(s.Label))
+		}
+		if s.LabelMerge != nil {
+			fmt.Fprintf(b, "%s.MergeLabel(%s)\n", v, goMerge(s.LabelMerge))
+		}
+	}
+	if len(s.Samples) > 0 {
+		fmt.Fprintf(b, "%s.Sample(%s)\n", v, quoteAll(s.Samples))
+	}
+	if len(s.SampleContents) > 0 {
+		fmt.Fprintf(b, "%s.SampleContent(%s)\n", v, quoteAll(s.SampleContents))
+	}
+	for _, o := range s.Options {
+		if tags && o.Sample == nil {
+			continue
+		}
+		args := []string{o.Field}
+		if o.Pattern != "" {
+			args = append(args, o.Pattern)
+		}
+		fmt.Fprintf(b, "%s.Option(%s)", v, quoteAll(args))
+		if o.Merge != nil {
+			fmt.Fprintf(b, ".Merge(%s)", goMerge(o.Merge))
+		}
+		if o.Sample != nil {
+			fmt.Fprintf(b, ".Sample(%s)", goLiteral(o.Sample))
+		}
+		b.WriteString("\n")
+		g.writeBool(b, fmt.Sprintf("%s.Option(%s)", v, quoteAll(args)), o.Bool)
+	}
+	for _, c := range s.CodeFences {
+		var table bytes.Buffer
+		if c.Table != nil {
+			table = g.tableBuilder(*c.Table)
+		} else if !tags && (c.Format == "csv" || c.Format == "tsv") {
+			table.WriteString("table.AsMap()\n")
+		}
+		if tags && c.SampleCode == "" && c.SampleInfo == "" && table.Len() == 0 {
+			continue
+		}
+		if table.Len() > 0 {
+			b.WriteString("{\ntable := ")
+		}
+		fmt.Fprintf(b, "%s.CodeFence(%s)", v, quoteAll(append([]string{c.Field}, c.Languages...)))
+		if !tags && c.Language != "" {
+			fmt.Fprintf(b, ".Language(%s)", strconv.Quote(c.Language))
+		}
+		if !tags && c.Info != "" {
+			fmt.Fprintf(b, ".Info(%s)", strconv.Quote(c.Info))
+		}
+		if !tags {
+			for _, name := range sortedKeys(c.Attrs) {
+				fmt.Fprintf(b, ".Attr(%s, %s)", strconv.Quote(name), strconv.Quote(c.Attrs[name]))
+			}
+		}
+		if c.Merge != nil {
+			fmt.Fprintf(b, ".Merge(%s)", goMerge(c.Merge))
+		}
+		for _, name := range c.Validate {
+			fmt.Fprintf(b, ".Validate(mdd.%s)", goValidators[name])
+		}
+		if c.IgnoreIndented {
+			b.WriteString(".IgnoreIndented()")
+		}
+		if c.KeepWhitespace {
+			b.WriteString(".KeepWhitespace()")
+		}
+		if c.Dedent {
+			b.WriteString(".Dedent()")
+		}
+		if c.NormalizeNewline {
+			b.WriteString(".NormalizeNewline()")
+		}
+		if c.SampleCode != "" {
+			fmt.Fprintf(b, ".SampleCode(%s)", strconv.Quote(c.SampleCode))
+		}
+		if c.SampleInfo != "" {
+			fmt.Fprintf(b, ".SampleInfo(%s)", strconv.Quote(c.SampleInfo))
+		}
+		if c.Format != "" && (!tags || table.Len() > 0) {
+			fmt.Fprintf(b, ".%s()", strings.ToUpper(c.Format))
+		}
+		b.WriteString("\n")
+		if table.Len() > 0 {
+			b.Write(table.Bytes())
+			b.WriteString("}\n")
+		}
+	}
+	if s.Table != nil {
+		if body := g.tableBuilder(*s.Table); body.Len() > 0 {
+			fmt.Fprintf(b, "table := %s.Table(%s)\n", v, strconv.Quote(s.Table.Field))
+			b.Write(body.Bytes())
+		} else if !tags {
+			fmt.Fprintf(b, "%s.Table(%s)\n", v, strconv.Quote(s.Table.Field))
+		}
+	}
+	for _, c := range s.Children {
+		method := "Child"
+		if c.Repeat {
+			method = "Children"
+		}
+		args := []string{c.Field}
+		if c.Pattern != "" {
+			args = append(args, c.Pattern)
+		}
+		childVar := fmt.Sprintf("level%d", level+1)
+		var body bytes.Buffer
+		g.writeBuilder(&body, childVar, c, level+1)
+		if body.Len() > 0 {
+			fmt.Fprintf(b, "{\n%s := %s.%s(%s)\n", childVar, v, method, quoteAll(args))
+			b.Write(body.Bytes())
+			b.WriteString("}\n")
+		} else if !tags {
+			fmt.Fprintf(b, "%s.%s(%s)\n", v, method, quoteAll(args))
+		}
+	}
+}
+
+// tableBuilder returns method calls for the table stored in variable "table".
+//
+// In tags mode, it returns only definitions that can't be expressed by tags (samples).
+func (g *goGenerator) tableBuilder(s SchemaTable) bytes.Buffer {
+	tags := g.opt.Tags
+	var body bytes.Buffer
+	if !tags && s.AsMap {
+		body.WriteString("table.AsMap()\n")
+	}
+	if !tags && s.KeyBy != "" {
+		fmt.Fprintf(&body, "table.KeyBy(%s)\n", strconv.Quote(s.KeyBy))
+	}
+	if !tags && s.Transpose {
+		body.WriteString("table.Transpose()\n")
+	}
+	if !tags && s.Extra != "" {
+		fmt.Fprintf(&body, "table.Extra(%s)\n", strconv.Quote(s.Extra))
+	}
+	if s.Strict {
+		body.WriteString("table.Strict()\n")
+	}
+	if s.Merge != nil {
+		fmt.Fprintf(&body, "table.Merge(mdd.%s)\n", goMergePolicies[s.Merge.Policy])
+	}
+	for _, f := range s.Fields {
+		if tags && len(f.Samples) == 0 {
+			continue
+		}
+		var method string
+		args := []string{f.Field}
+		if f.Group != nil {
+			method = "FieldGroup"
+			args = append(args, f.Group...)
+		} else {
+			method = "Field"
+			if f.Key != "" {
+				args = append(args, f.Key)
+			}
+		}
+		fmt.Fprintf(&body, "table.%s(%s)", method, quoteAll(args))
+		if !tags && f.Required {
+			body.WriteString(".Required()")
+		}
+		if sp := f.Split; sp != nil {
+			switch {
+			case sp.Regexp != "":
+				fmt.Fprintf(&body, ".SplitRegexp(regexp.MustCompile(%s))", strconv.Quote(sp.Regexp))
+			case sp.Separator != "":
+				fmt.Fprintf(&body, ".Split(%s)", strconv.Quote(sp.Separator))
+			default:
+				body.WriteString(`.Split(",")`)
+			}
+			if sp.Trim != "" {
+				fmt.Fprintf(&body, ".Trim(%s)", strconv.Quote(sp.Trim))
+			}
+			if sp.KeepSpace {
+				body.WriteString(".KeepSpace()")
+			}
+		}
+		if len(f.Samples) > 0 {
+			lits := make([]string, len(f.Samples))
+			for i, v := range f.Samples {
+				lits[i] = goLiteral(v)
+			}
+			fmt.Fprintf(&body, ".Samples(%s)", strings.Join(lits, ", "))
+		}
+		body.WriteString("\n")
+		g.writeBool(&body, fmt.Sprintf("table.%s(%s)", method, quoteAll(args)), f.Bool)
+	}
+	return body
+}
+
+// writeBool writes calls of BoolWords methods. expr is the expression that has Bool method.
+func (g *goGenerator) writeBool(b *bytes.Buffer, expr string, words []SchemaBool) {
+	for _, w := range words {
+		fmt.Fprintf(b, "%s.Bool()", expr)
+		if w.Lang != "" {
+			fmt.Fprintf(b, ".Lang(%s)", strconv.Quote(w.Lang))
+		}
+		if len(w.True) > 0 {
+			fmt.Fprintf(b, ".True(%s)", quoteAll(w.True))
+		}
+		if len(w.False) > 0 {
+			fmt.Fprintf(b, ".False(%s)", quoteAll(w.False))
+		}
+		b.WriteString("\n")
+	}
+}
+
+var goMergePolicies = map[string]string{
+	"error":  "MergeError",
+	"append": "MergeAppend",
+	"concat": "MergeConcat",
+	"first":  "MergeFirst",
+	"last":   "MergeLast",
+}
+
+func goMerge(m *SchemaMerge) string {
+	result := "mdd." + goMergePolicies[m.Policy]
+	if m.Separator != nil {
+		result += ", " + strconv.Quote(*m.Separator)
+	}
+	return result
+}
+
+var goValidators = map[string]string{
+	"json": "ValidateJSON",
+	"yaml": "ValidateYAML",
+	"go":   "ValidateGo",
+	"sql":  "ValidateSQL",
+}
+
+func quoteAll(values []string) string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strconv.Quote(v)
+	}
+	return strings.Join(result, ", ")
+}
+
+func goLiteral(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64, float32:
+		return fmt.Sprintf("%v", v)
+	}
+	return fmt.Sprintf("%#v", v)
+}
@@ -0,0 +1,199 @@
+package mdd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_GenerateGo(t *testing.T) {
+	schemaSrc := TrimIndent(t, `
+	aliases:
+	  - label: CRUD Matrix
+	    langs:
+	      ja: [CRUDマトリックス]
+	root:
+	  label: Name
+	  codeFences:
+	    - field: SQL
+	      languages: [sql]
+	      sampleCode: select * from users;
+	  children:
+	    - field: .
+	      pattern: CRUD Matrix
+	      table:
+	        field: CRUDMatrix
+	        fields:
+	          - field: Table
+	            required: true
+	          - field: R
+	            type: bool
+	            samples: [true]
+	    - field: Queries
+	      pattern: Query
+	      repeat: true
+	      label: Name
+	      options:
+	        - field: Limit
+	          type: int
+	`)
+	schema, err := LoadSchema(strings.NewReader(schemaSrc))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opt  GenerateGoOption
+		want string
+	}{
+		{
+			name: "builder methods",
+			opt: GenerateGoOption{
+				Package:  "sample",
+				TypeName: "SQLDoc",
+			},
+			want: TrimIndent(t, `
+			// Code generated by mdd gen; DO NOT EDIT.
+
+			package sample
+
+			import "github.com/shibukawa/mdd-go"
+
+			type SQLDoc struct {
+				Name       string
+				SQL        string
+				CRUDMatrix []SQLDocCRUDMatrix
+				Queries    []SQLDocQueries
+			}
+
+			type SQLDocCRUDMatrix struct {
+				Table string
+				R     bool
+			}
+
+			type SQLDocQueries struct {
+				Name  string
+				Limit int
+			}
+
+			var SQLDocJig = mdd.NewDocJig[SQLDoc]()
+
+			func init() {
+				SQLDocJig.Alias("CRUD Matrix").Lang("ja", "CRUDマトリックス")
+				root := SQLDocJig.Root()
+				root.Label("Name")
+				root.CodeFence("SQL", "sql").SampleCode("select * from users;")
+				{
+					level2 := root.Child(".", "CRUD Matrix")
+					table := level2.Table("CRUDMatrix")
+					table.Field("Table").Required()
+					table.Field("R").Samples(true)
+				}
+				{
+					level2 := root.Children("Queries", "Query")
+					level2.Label("Name")
+					level2.Option("Limit")
+				}
+			}
+			`),
+		},
+		{
+			name: "tags",
+			opt: GenerateGoOption{
+				Package:  "sample",
+				TypeName: "SQLDoc",
+				JigName:  "sqlJig",
+				Tags:     true,
+			},
+			want: TrimIndent(t, `
+			// Code generated by mdd gen; DO NOT EDIT.
+
+			package sample
+
+			import "github.com/shibukawa/mdd-go"
+
+			type SQLDoc struct {
+				Name       string             `+"`"+`mdd:"label"`+"`"+`
+				SQL        string             `+"`"+`mdd:"fence=sql"`+"`"+`
+				CRUDMatrix []SQLDocCRUDMatrix `+"`"+`mdd:"child=CRUD Matrix,table"`+"`"+`
+				Queries    []SQLDocQueries    `+"`"+`mdd:"children=Query"`+"`"+`
+			}
+
+			type SQLDocCRUDMatrix struct {
+				Table string `+"`"+`mdd:"required"`+"`"+`
+				R     bool
+			}
+
+			type SQLDocQueries struct {
+				Name  string `+"`"+`mdd:"label"`+"`"+`
+				Limit int    `+"`"+`mdd:"option"`+"`"+`
+			}
+
+			var sqlJig = mdd.NewDocJigFromTags[SQLDoc]()
+
+			func init() {
+				sqlJig.Alias("CRUD Matrix").Lang("ja", "CRUDマトリックス")
+				root := sqlJig.Root()
+				root.CodeFence("SQL", "sql").SampleCode("select * from users;")
+				{
+					level2 := root.Child(".", "CRUD Matrix")
+					table := level2.Table("CRUDMatrix")
+					table.Field("R").Samples(true)
+				}
+			}
+			`),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := schema.GenerateGo(&buffer, tc.opt)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, strings.TrimRight(buffer.String(), "\n"))
+		})
+	}
+}
+
+func TestSchema_GenerateGo_TagValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		wantTags bool
+		want     string
+	}{
+		{
+			name:     "equal and quote",
+			key:      `a=b "c"`,
+			wantTags: true,
+			want:     "`mdd:\"column=a=b \\\"c\\\"\"`",
+		},
+		{
+			name: "comma",
+			key:  "a,b",
+			want: `table.Field("A", "a,b")`,
+		},
+		{
+			name: "backquote",
+			key:  "`a`",
+			want: "table.Field(\"A\", \"`a`\")",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := &Schema{
+				Root: SchemaLayout{
+					Table: &SchemaTable{
+						Field:  "Rows",
+						Fields: []SchemaField{{Field: "A", Key: tc.key}},
+					},
+				},
+			}
+			var b bytes.Buffer
+			err := schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true})
+			assert.NoError(t, err)
+			assert.Contains(t, b.String(), tc.want)
+			assert.Equal(t, tc.wantTags, strings.Contains(b.String(), "NewDocJigFromTags"))
+		})
+	}
+}
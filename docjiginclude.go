@@ -0,0 +1,203 @@
+package mdd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// includer loads files for code fences that have include attribute
+//
+//	```sql include=queries/find_user.sql
+//	```
+//
+// Paths are relative to the document. "lines" attribute selects line ranges ("3-10,12") and
+// "region" attribute selects lines between "#region name" and "#endregion" markers:
+//
+//	```go include=main.go region=handler
+//	```
+//
+// Included markdown files (.md, .markdown) can include other files. Cycles are reported as errors.
+// Absolute paths and paths outside the directory of the document are rejected.
+type includer struct {
+	read  func(name string) ([]byte, error)
+	join  func(base, name string) (string, error)
+	stack []string // including files (the document is the first)
+}
+
+var errIncludeOutside = errors.New("path should be relative and inside the directory of the document")
+
+func newOSIncluder(filename string) *includer {
+	root := filepath.Dir(filepath.Clean(filename))
+	return &includer{
+		read: os.ReadFile,
+		join: func(base, name string) (string, error) {
+			if filepath.IsAbs(name) || path.IsAbs(name) {
+				return "", errIncludeOutside
+			}
+			p := filepath.Join(filepath.Dir(base), filepath.FromSlash(name))
+			if rel, err := filepath.Rel(root, p); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return "", errIncludeOutside
+			}
+			return p, nil
+		},
+		stack: []string{filepath.Clean(filename)},
+	}
+}
+
+func newFSIncluder(fsys fs.FS, filename string) *includer {
+	root := path.Dir(path.Clean(filename))
+	return &includer{
+		read: func(name string) ([]byte, error) {
+			return fs.ReadFile(fsys, name)
+		},
+		join: func(base, name string) (string, error) {
+			if path.IsAbs(name) {
+				return "", errIncludeOutside
+			}
+			p := path.Join(path.Dir(base), name)
+			if root != "." && p != root && !strings.HasPrefix(p, root+"/") || p == ".." || strings.HasPrefix(p, "../") {
+				return "", errIncludeOutside
+			}
+			return p, nil
+		},
+		stack: []string{path.Clean(filename)},
+	}
+}
+
+// load returns the content of included file selected by lines and region, and line numbers in the file
+// of each line of the content (nil if they are unknown because of nested includes)
+func (in *includer) load(name, lines, region string) (string, []int, error) {
+	if in == nil {
+		return "", nil, fmt.Errorf("can't include '%s': include is available only with ParseFile, ParseGlob or ParseFS", name)
+	}
+	p, err := in.join(in.stack[len(in.stack)-1], name)
+	if err != nil {
+		return "", nil, fmt.Errorf("can't include '%s': %w", name, err)
+	}
+	for _, s := range in.stack {
+		if s == p {
+			return "", nil, fmt.Errorf("can't include '%s': include cycle %s", name, strings.Join(append(in.stack, p), " -> "))
+		}
+	}
+	b, err := in.read(p)
+	if err != nil {
+		return "", nil, fmt.Errorf("can't include '%s': %w", name, err)
+	}
+	content := string(b)
+	numbers := make([]int, strings.Count(content, "\n")+1)
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+	if ext := strings.ToLower(path.Ext(p)); ext == ".md" || ext == ".markdown" {
+		nested := &includer{read: in.read, join: in.join, stack: append(in.stack[:len(in.stack):len(in.stack)], p)}
+		expanded, err := nested.expand(content)
+		if err != nil {
+			return "", nil, err
+		}
+		if expanded != content {
+			content, numbers = expanded, nil
+		}
+	}
+	if region != "" {
+		content, numbers, err = selectRegion(content, numbers, region)
+		if err != nil {
+			return "", nil, fmt.Errorf("can't include '%s': %w", name, err)
+		}
+	}
+	if lines != "" {
+		content, numbers, err = selectLines(content, numbers, lines)
+		if err != nil {
+			return "", nil, fmt.Errorf("can't include '%s': %w", name, err)
+		}
+	}
+	return content, numbers, nil
+}
+
+// expand replaces bodies of include fences in markdown src with included files
+func (in *includer) expand(src string) (string, error) {
+	lines := strings.Split(src, "\n")
+	var result []string
+	next := 0
+	for _, f := range newFenceLocator(src).fences {
+		fi := parseFenceInfo([]byte(f.info))
+		name := fi.attrs["include"]
+		if name == "" || f.end == 0 {
+			continue
+		}
+		content, _, err := in.load(name, fi.attrs["lines"], fi.attrs["region"])
+		if err != nil {
+			return "", fmt.Errorf("code fence at line %d of '%s': %w", f.line, in.stack[len(in.stack)-1], err)
+		}
+		result = append(result, lines[next:f.line]...)
+		if content != "" {
+			result = append(result, content)
+		}
+		result = append(result, lines[f.end-1])
+		next = f.end
+	}
+	result = append(result, lines[next:]...)
+	return strings.Join(result, "\n"), nil
+}
+
+// selectLines selects lines of content by ranges. numbers are line numbers in the file of each line (can be nil).
+func selectLines(content string, numbers []int, ranges string) (string, []int, error) {
+	selected, err := parseLineRanges(ranges)
+	if err != nil {
+		return "", nil, err
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	result := make([]string, 0, len(selected))
+	var resultNumbers []int
+	for _, n := range selected {
+		if n < 1 || n > len(lines) {
+			return "", nil, fmt.Errorf("line %d is out of range (%d lines)", n, len(lines))
+		}
+		result = append(result, lines[n-1])
+		if numbers != nil {
+			resultNumbers = append(resultNumbers, numbers[n-1])
+		}
+	}
+	return strings.Join(result, "\n"), resultNumbers, nil
+}
+
+var regionMarker = regexp.MustCompile(`#(end)?region\b\s*(\S*)`)
+
+// selectRegion selects lines of the region. numbers are line numbers in the file of each line (can be nil).
+func selectRegion(content string, numbers []int, region string) (string, []int, error) {
+	var result []string
+	var resultNumbers []int
+	inRegion, found := false, false
+	depth := 0 // nested regions inside the region
+	for i, line := range strings.Split(content, "\n") {
+		m := regionMarker.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+			if inRegion {
+				result = append(result, line)
+				if numbers != nil {
+					resultNumbers = append(resultNumbers, numbers[i])
+				}
+			}
+		case !inRegion:
+			if m[1] == "" && m[2] == region {
+				inRegion, found = true, true
+			}
+		case m[1] == "":
+			depth++
+		case depth > 0:
+			depth--
+		default:
+			inRegion = false
+		}
+	}
+	if !found {
+		return "", nil, fmt.Errorf("region '%s' is not found", region)
+	}
+	return strings.Join(result, "\n"), resultNumbers, nil
+}
@@ -0,0 +1,169 @@
+package mdd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInclude(t *testing.T) {
+	type Doc struct {
+		SQL string
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Root().CodeFence("SQL", "sql", "md").NormalizeNewline()
+
+	fsys := fstest.MapFS{
+		"docs/queries/find_user.sql": {Data: []byte("-- find user\r\n-- #region body\r\nselect *\r\nfrom users;\r\n-- #endregion\r\n")},
+		"docs/part.md":               {Data: []byte("# Part\n\n```sql include=queries/find_user.sql lines=3\n```\n")},
+		"secret.sql":                 {Data: []byte("select password from users;\n")},
+		"docs/loop1.md":              {Data: []byte("```md include=loop2.md\n```\n")},
+		"docs/loop2.md":              {Data: []byte("```md include=loop1.md\n```\n")},
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "whole file",
+			src:  "# Doc\n\n```sql include=queries/find_user.sql\nignored\n```\n",
+			want: "-- find user\n-- #region body\nselect *\nfrom users;\n-- #endregion",
+		},
+		{
+			name: "lines",
+			src:  "# Doc\n\n```sql include=queries/find_user.sql lines=3-4\n```\n",
+			want: "select *\nfrom users;",
+		},
+		{
+			name: "region",
+			src:  "# Doc\n\n```sql include=\"queries/find_user.sql\" region=body\n```\n",
+			want: "select *\nfrom users;",
+		},
+		{
+			name: "nested markdown",
+			src:  "# Doc\n\n```md include=part.md\n```\n",
+			want: "# Part\n\n```sql include=queries/find_user.sql lines=3\nselect *\n```",
+		},
+		{
+			name:    "missing file",
+			src:     "# Doc\n\n```sql include=queries/missing.sql\n```\n",
+			wantErr: "code fence at line 3 (inside 'Doc' section): can't include 'queries/missing.sql': open docs/queries/missing.sql: file does not exist",
+		},
+		{
+			name:    "missing region",
+			src:     "# Doc\n\n```sql include=queries/find_user.sql region=head\n```\n",
+			wantErr: "code fence at line 3 (inside 'Doc' section): can't include 'queries/find_user.sql': region 'head' is not found",
+		},
+		{
+			name:    "line out of range",
+			src:     "# Doc\n\n```sql include=queries/find_user.sql lines=5-6\n```\n",
+			wantErr: "code fence at line 3 (inside 'Doc' section): can't include 'queries/find_user.sql': line 6 is out of range (5 lines)",
+		},
+		{
+			name:    "outside of document directory",
+			src:     "# Doc\n\n```sql include=../secret.sql\n```\n",
+			wantErr: "code fence at line 3 (inside 'Doc' section): can't include '../secret.sql': path should be relative and inside the directory of the document",
+		},
+		{
+			name:    "absolute path",
+			src:     "# Doc\n\n```sql include=/docs/queries/find_user.sql\n```\n",
+			wantErr: "code fence at line 3 (inside 'Doc' section): can't include '/docs/queries/find_user.sql': path should be relative and inside the directory of the document",
+		},
+		{
+			name:    "cycle",
+			src:     "# Doc\n\n```md include=loop1.md\n```\n",
+			wantErr: "code fence at line 3 (inside 'Doc' section): code fence at line 1 of 'docs/loop1.md': code fence at line 1 of 'docs/loop2.md': can't include 'loop1.md': include cycle docs/doc.md -> docs/loop1.md -> docs/loop2.md -> docs/loop1.md",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys["docs/doc.md"] = &fstest.MapFile{Data: []byte(tc.src)}
+			got, err := jig.ParseFS(fsys, "docs/doc.md")
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got["docs/doc.md"].SQL)
+			}
+		})
+	}
+}
+
+func TestInclude_ParseFile(t *testing.T) {
+	type Doc struct {
+		SQL string
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Root().CodeFence("SQL", "sql")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "queries"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "queries", "find_user.sql"), []byte("select * from users;\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc\n\n```sql include=queries/find_user.sql\n```\n"), 0o644))
+
+	got, err := jig.ParseFile(filepath.Join(dir, "doc.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users;", got.SQL)
+
+	abs := filepath.ToSlash(filepath.Join(dir, "queries", "find_user.sql"))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "abs.md"), []byte("# Doc\n\n```sql include="+abs+"\n```\n"), 0o644))
+	_, err = jig.ParseFile(filepath.Join(dir, "abs.md"))
+	assert.EqualError(t, err, "code fence at line 3 (inside 'Doc' section): can't include '"+abs+"': path should be relative and inside the directory of the document")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "queries", "parent.md"), []byte("# Doc\n\n```sql include=../../secret.sql\n```\n"), 0o644))
+	_, err = jig.ParseFile(filepath.Join(dir, "queries", "parent.md"))
+	assert.EqualError(t, err, "code fence at line 3 (inside 'Doc' section): can't include '../../secret.sql': path should be relative and inside the directory of the document")
+
+	_, err = jig.ParseString("# Doc\n\n```sql include=queries/find_user.sql\n```\n")
+	assert.EqualError(t, err, "code fence at line 3 (inside 'Doc' section): can't include 'queries/find_user.sql': include is available only with ParseFile, ParseGlob or ParseFS")
+}
+
+func TestInclude_Validate(t *testing.T) {
+	type Doc struct {
+		SQL string
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Root().CodeFence("SQL", "sql").Validate(ValidateSQL)
+
+	fsys := fstest.MapFS{
+		"docs/queries/broken.sql": {Data: []byte("\n-- #region body\nselect 1;\nselect 'abc from users;\n-- #endregion\n")},
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name:    "whole file",
+			src:     "# Doc\n\n```sql include=queries/broken.sql\n```\n",
+			wantErr: "invalid sql code fence at line 3 (inside 'Doc' section): line 4, column 8 of 'queries/broken.sql': unterminated string literal",
+		},
+		{
+			name:    "region",
+			src:     "# Doc\n\n```sql include=queries/broken.sql region=body\n```\n",
+			wantErr: "invalid sql code fence at line 3 (inside 'Doc' section): line 4, column 8 of 'queries/broken.sql': unterminated string literal",
+		},
+		{
+			name:    "lines",
+			src:     "# Doc\n\n```sql include=queries/broken.sql lines=4\n```\n",
+			wantErr: "invalid sql code fence at line 3 (inside 'Doc' section): line 4, column 8 of 'queries/broken.sql': unterminated string literal",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys["docs/doc.md"] = &fstest.MapFile{Data: []byte(tc.src)}
+			_, err := jig.ParseFS(fsys, "docs/doc.md")
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
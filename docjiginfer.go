@@ -0,0 +1,462 @@
+package mdd
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// InferSample is a sample markdown document for [InferSchema]
+type InferSample struct {
+	Name   string
+	Source string
+}
+
+// InferWarning reports the place where samples disagree
+type InferWarning struct {
+	Section string // Heading path like "Root > Child"
+	Message string
+}
+
+func (w InferWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Section, w.Message)
+}
+
+// InferSchema derives [Schema] from sample documents
+//
+// It finds common heading hierarchy, repeated sections, code fence languages and
+// table columns. The result is a starting point to define jig. Write it by [Schema.WriteYAML]
+// or generate Go code by [Schema.GenerateGo].
+//
+// Places where samples disagree (missing sections, missing columns and so on) are
+// returned as warnings.
+func InferSchema(samples ...InferSample) (*Schema, []InferWarning) {
+	inf := &inferrer{}
+	var roots []*sampleSection
+	for _, s := range samples {
+		sections := parseSampleSections(s.Name, s.Source)
+		var levelOnes []*sampleSection
+		for _, sec := range sections {
+			if sec.level == 1 {
+				levelOnes = append(levelOnes, sec)
+			}
+		}
+		switch len(levelOnes) {
+		case 0:
+			inf.warn("(root)", "%s doesn't have level 1 heading", s.Name)
+			continue
+		case 1:
+		default:
+			inf.warn("(root)", "%s has %d level 1 headings; only first one is used", s.Name, len(levelOnes))
+		}
+		roots = append(roots, levelOnes[0])
+	}
+	schema := &Schema{}
+	if len(roots) > 0 {
+		st := newInferStruct()
+		schema.Root.Label = st.field("Name")
+		inf.inferLayout(&schema.Root, st, roots, "(root)")
+	}
+	return schema, inf.warnings
+}
+
+// InferSchemaFS is a variation of [InferSchema] that reads samples from fs.FS
+func InferSchemaFS(fsys fs.FS, patterns ...string) (*Schema, []InferWarning, error) {
+	var samples []InferSample
+	for _, pattern := range patterns {
+		list, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(list) == 0 {
+			return nil, nil, fmt.Errorf("pattern matches no files: %#q", pattern)
+		}
+		for _, filename := range list {
+			st, err := fs.Stat(fsys, filename)
+			if err != nil {
+				return nil, nil, err
+			}
+			if st.IsDir() {
+				continue
+			}
+			c, err := fs.ReadFile(fsys, filename)
+			if err != nil {
+				return nil, nil, err
+			}
+			samples = append(samples, InferSample{Name: filename, Source: string(c)})
+		}
+	}
+	schema, warnings := InferSchema(samples...)
+	return schema, warnings, nil
+}
+
+type sampleTable struct {
+	headers []string
+	rows    [][]string
+}
+
+type sampleSection struct {
+	file     string
+	title    string
+	level    int
+	fences   []string
+	tables   []sampleTable
+	children []*sampleSection
+}
+
+func parseSampleSections(name, src string) []*sampleSection {
+	parser := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	root := parser.Parse([]byte(src))
+
+	var result []*sampleSection
+	var stack []*sampleSection
+	for node := root.FirstChild; node != nil; node = node.Next {
+		switch node.Type {
+		case blackfriday.Heading:
+			sec := &sampleSection{
+				file:  name,
+				title: strings.TrimSpace(plainTextRenderer(node.FirstChild)),
+				level: node.Level,
+			}
+			for len(stack) > 0 && stack[len(stack)-1].level >= sec.level {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, sec)
+			} else {
+				result = append(result, sec)
+			}
+			stack = append(stack, sec)
+		case blackfriday.CodeBlock:
+			if len(stack) > 0 {
+				lang, _ := parseCodeBlockType(node.CodeBlockData.Info)
+				sec := stack[len(stack)-1]
+				sec.fences = append(sec.fences, lang)
+			}
+		case blackfriday.Table:
+			if len(stack) > 0 {
+				headers, rows := parseTable(node)
+				sec := stack[len(stack)-1]
+				sec.tables = append(sec.tables, sampleTable{headers: headers, rows: rows})
+			}
+		}
+	}
+	return result
+}
+
+type inferrer struct {
+	warnings []InferWarning
+}
+
+func (inf *inferrer) warn(section, format string, args ...any) {
+	inf.warnings = append(inf.warnings, InferWarning{
+		Section: section,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// inferStruct manages field names of one struct to avoid collision
+type inferStruct struct {
+	names map[string]bool
+}
+
+func newInferStruct() *inferStruct {
+	return &inferStruct{names: make(map[string]bool)}
+}
+
+func (s *inferStruct) field(base string) string {
+	name := base
+	for i := 2; s.names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	s.names[name] = true
+	return name
+}
+
+func splitPattern(title string) (pattern, suffix string, hasSuffix bool) {
+	if p, s, ok := strings.Cut(title, ":"); ok {
+		return strings.TrimSpace(p), strings.TrimSpace(s), true
+	}
+	return title, "", false
+}
+
+func sectionPath(path, title string) string {
+	return path + " > " + title
+}
+
+func fileNames(sections []*sampleSection) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range sections {
+		if !seen[s.file] {
+			seen[s.file] = true
+			names = append(names, s.file)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+type childGroup struct {
+	pattern   string
+	sections  []*sampleSection
+	parents   map[*sampleSection]int
+	hasSuffix bool
+}
+
+func (inf *inferrer) inferLayout(sl *SchemaLayout, st *inferStruct, instances []*sampleSection, path string) {
+	// code fences
+	var langs []string
+	langCount := make(map[string]bool)
+	for _, sec := range instances {
+		count := make(map[string]int)
+		for _, lang := range sec.fences {
+			count[lang]++
+			if !langCount[lang] {
+				langCount[lang] = true
+				langs = append(langs, lang)
+			}
+		}
+		for _, lang := range sortedKeys(count) {
+			if count[lang] > 1 {
+				inf.warn(path, "%s has %d code fences of language '%s' in one section", sec.file, count[lang], lang)
+			}
+		}
+	}
+	for _, lang := range langs {
+		cf := SchemaCodeFence{}
+		if lang == "" {
+			cf.Field = st.field("Code")
+		} else {
+			cf.Field = st.field(goFieldName(lang))
+			cf.Languages = []string{lang}
+		}
+		sl.CodeFences = append(sl.CodeFences, cf)
+	}
+
+	// table
+	var tables []sampleTable
+	for _, sec := range instances {
+		if len(sec.tables) > 1 {
+			inf.warn(path, "%s has %d tables in one section; only first one is used", sec.file, len(sec.tables))
+		}
+		if len(sec.tables) > 0 {
+			tables = append(tables, sec.tables[0])
+		}
+	}
+	if len(tables) > 0 {
+		field := "Table"
+		if sl.Field == "." {
+			field = goFieldName(sl.Pattern)
+		}
+		sl.Table = inf.inferTable(path, st.field(field), tables)
+	}
+
+	// child sections
+	var groups []*childGroup
+	groupIndex := make(map[string]*childGroup)
+	for _, sec := range instances {
+		for _, c := range sec.children {
+			pattern, _, hasSuffix := splitPattern(c.title)
+			key := strings.ToLower(pattern)
+			g, ok := groupIndex[key]
+			if !ok {
+				g = &childGroup{pattern: pattern, parents: make(map[*sampleSection]int)}
+				groupIndex[key] = g
+				groups = append(groups, g)
+			}
+			g.sections = append(g.sections, c)
+			g.parents[sec]++
+			if hasSuffix {
+				g.hasSuffix = true
+			}
+		}
+	}
+	var variable []*sampleSection
+	for _, g := range groups {
+		repeat := false
+		for _, count := range g.parents {
+			if count > 1 {
+				repeat = true
+			}
+		}
+		if !repeat && !g.hasSuffix && len(instances) > 1 && len(g.parents) == 1 && len(groups) > 1 {
+			// title appears only once in samples: treat as variable title
+			variable = append(variable, g.sections...)
+			continue
+		}
+		childPath := sectionPath(path, g.pattern)
+		if len(g.parents) < len(instances) {
+			var missing []*sampleSection
+			for _, sec := range instances {
+				if _, ok := g.parents[sec]; !ok {
+					missing = append(missing, sec)
+				}
+			}
+			inf.warn(childPath, "section is missing in %s", fileNames(missing))
+		}
+		child := SchemaLayout{
+			Pattern: g.pattern,
+			Repeat:  repeat,
+		}
+		onlyTable := !repeat && !g.hasSuffix
+		for _, c := range g.sections {
+			if len(c.fences) > 0 || len(c.children) > 0 || len(c.tables) == 0 {
+				onlyTable = false
+			}
+		}
+		if onlyTable {
+			child.Field = "."
+			inf.inferLayout(&child, st, g.sections, childPath)
+		} else {
+			child.Field = st.field(goFieldName(g.pattern))
+			cst := newInferStruct()
+			if g.hasSuffix || repeat {
+				child.Label = cst.field("Name")
+			}
+			inf.inferLayout(&child, cst, g.sections, childPath)
+		}
+		sl.Children = append(sl.Children, child)
+	}
+	if len(variable) > 0 {
+		var titles []string
+		for _, v := range variable {
+			titles = append(titles, fmt.Sprintf("'%s' (%s)", v.title, v.file))
+		}
+		inf.warn(path, "section titles differ between samples; treated as repeated sections: %s", strings.Join(titles, ", "))
+		child := SchemaLayout{
+			Field:  st.field("Sections"),
+			Repeat: true,
+		}
+		cst := newInferStruct()
+		child.Label = cst.field("Name")
+		inf.inferLayout(&child, cst, variable, sectionPath(path, "*"))
+		sl.Children = append(sl.Children, child)
+	}
+}
+
+func (inf *inferrer) inferTable(path, field string, tables []sampleTable) *SchemaTable {
+	result := &SchemaTable{Field: field}
+	type column struct {
+		header string
+		count  int
+		values []string
+	}
+	var columns []*column
+	index := make(map[string]*column)
+	for _, t := range tables {
+		for i, h := range t.headers {
+			key := strings.ToLower(h)
+			c, ok := index[key]
+			if !ok {
+				c = &column{header: h}
+				index[key] = c
+				columns = append(columns, c)
+			}
+			c.count++
+			for _, r := range t.rows {
+				c.values = append(c.values, r[i])
+			}
+		}
+	}
+	rowStruct := newInferStruct()
+	for _, c := range columns {
+		f := SchemaField{
+			Field:    rowStruct.field(goFieldName(c.header)),
+			Required: c.count == len(tables),
+			Type:     inferType(c.values),
+		}
+		if f.Field != c.header {
+			f.Key = c.header
+		}
+		if !f.Required {
+			inf.warn(path, "column '%s' exists only in %d of %d tables", c.header, c.count, len(tables))
+		}
+		result.Fields = append(result.Fields, f)
+	}
+	return result
+}
+
+var inferBoolWords = map[string]bool{
+	"x": true, "o": true, "✓": true, "✔": true, "○": true, "●": true, "yes": true, "true": true,
+	"no": false, "false": false, "-": false,
+}
+
+// inferType guesses schema type from cell values. Blank cells are ignored.
+func inferType(values []string) string {
+	isInt, isFloat, isBool := true, true, true
+	found := false
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		found = true
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			isInt = false
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			isFloat = false
+		}
+		if _, ok := inferBoolWords[strings.ToLower(v)]; !ok {
+			isBool = false
+		}
+	}
+	switch {
+	case !found:
+		return ""
+	case isInt:
+		return "int"
+	case isFloat:
+		return "float"
+	case isBool:
+		return "bool"
+	}
+	return ""
+}
+
+var goInitialisms = map[string]bool{
+	"api": true, "crud": true, "css": true, "csv": true, "html": true, "http": true, "id": true,
+	"json": true, "sh": true, "sql": true, "tsv": true, "uri": true, "url": true, "uuid": true,
+	"xml": true, "yaml": true,
+}
+
+// goFieldName converts label into exported Go identifier ("CRUD Matrix" → "CRUDMatrix", "user id" → "UserID").
+func goFieldName(label string) string {
+	words := strings.FieldsFunc(label, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if goInitialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		b.WriteRune(unicode.ToUpper(rs[0]))
+		b.WriteString(string(rs[1:]))
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if r := []rune(name)[0]; !unicode.IsUpper(r) {
+		// digits or letters without case (e.g. Japanese)
+		name = "F" + name
+	}
+	return name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
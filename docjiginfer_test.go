@@ -0,0 +1,161 @@
+package mdd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferSchema(t *testing.T) {
+	sample1 := TrimIndent(t, `
+	# Find User
+
+	~~~sql
+	select * from users where id = /*id*/1;
+	~~~
+
+	## CRUD Matrix
+
+	| Table | R | Limit |
+	|-------|---|-------|
+	| users | X | 10    |
+
+	## Query: by id
+
+	~~~sql
+	select * from users where id = 1;
+	~~~
+
+	## Query: by name
+
+	~~~sql
+	select * from users where name = 'bob';
+	~~~
+	`)
+	sample2 := TrimIndent(t, `
+	# Delete User
+
+	~~~sql
+	delete from users where id = /*id*/1;
+	~~~
+
+	## CRUD Matrix
+
+	| Table | D |
+	|-------|---|
+	| users | X |
+	`)
+
+	schema, warnings := InferSchema(
+		InferSample{Name: "find.md", Source: sample1},
+		InferSample{Name: "delete.md", Source: sample2},
+	)
+	var buffer bytes.Buffer
+	assert.NoError(t, schema.WriteYAML(&buffer))
+	want := TrimIndent(t, `
+	root:
+	  label: Name
+	  codeFences:
+	    - field: SQL
+	      languages:
+	        - sql
+	  children:
+	    - field: .
+	      pattern: CRUD Matrix
+	      table:
+	        field: CRUDMatrix
+	        fields:
+	          - field: Table
+	            required: true
+	          - field: R
+	            type: bool
+	          - field: Limit
+	            type: int
+	          - field: D
+	            type: bool
+	    - field: Query
+	      pattern: Query
+	      repeat: true
+	      label: Name
+	      codeFences:
+	        - field: SQL
+	          languages:
+	            - sql
+	`)
+	assert.Equal(t, want, strings.TrimRight(buffer.String(), "\n"))
+
+	var messages []string
+	for _, w := range warnings {
+		messages = append(messages, w.String())
+	}
+	assert.Equal(t, []string{
+		"(root) > CRUD Matrix: column 'R' exists only in 1 of 2 tables",
+		"(root) > CRUD Matrix: column 'Limit' exists only in 1 of 2 tables",
+		"(root) > CRUD Matrix: column 'D' exists only in 1 of 2 tables",
+		"(root) > Query: section is missing in delete.md",
+	}, messages)
+
+	// inferred schema can parse samples
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	got, err := jig.ParseString(sample1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Find User", (*got)["Name"])
+	assert.Len(t, (*got)["Query"], 2)
+}
+
+func TestInferSchema_VariableTitles(t *testing.T) {
+	schema, warnings := InferSchema(
+		InferSample{Name: "a.md", Source: "# Backup\n\n## Dump\n\n```sh\npg_dump\n```\n"},
+		InferSample{Name: "b.md", Source: "# Restore\n\n## Restore\n\n```sh\npg_restore\n```\n"},
+	)
+	assert.Equal(t, []SchemaLayout{
+		{
+			Field:      "Sections",
+			Repeat:     true,
+			Label:      "Name",
+			CodeFences: []SchemaCodeFence{{Field: "SH", Languages: []string{"sh"}}},
+		},
+	}, schema.Root.Children)
+	assert.Equal(t, []InferWarning{
+		{
+			Section: "(root)",
+			Message: "section titles differ between samples; treated as repeated sections: 'Dump' (a.md), 'Restore' (b.md)",
+		},
+	}, warnings)
+}
+
+func TestInferSchemaFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/a.md": {Data: []byte("# A\n\nno heading\n\n# B\n")},
+		"docs/b.md": {Data: []byte("text only\n")},
+	}
+	schema, warnings, err := InferSchemaFS(fsys, "docs/*.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "Name", schema.Root.Label)
+	assert.Equal(t, []InferWarning{
+		{Section: "(root)", Message: "docs/a.md has 2 level 1 headings; only first one is used"},
+		{Section: "(root)", Message: "docs/b.md doesn't have level 1 heading"},
+	}, warnings)
+
+	_, _, err = InferSchemaFS(fsys, "none/*.md")
+	assert.EqualError(t, err, "pattern matches no files: `none/*.md`")
+}
+
+func TestGoFieldName(t *testing.T) {
+	tests := map[string]string{
+		"CRUD Matrix": "CRUDMatrix",
+		"user id":     "UserID",
+		"sql":         "SQL",
+		"go":          "Go",
+		"2nd step":    "F2ndStep",
+		"説明":          "F説明",
+		"":            "Field",
+	}
+	for src, want := range tests {
+		assert.Equal(t, want, goFieldName(src), src)
+	}
+}
@@ -0,0 +1,105 @@
+package mdd
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// defaultLanguageAliases are language names of code fences that are treated as the same language.
+// The first name is primary.
+//
+// Other languages can be registered by [DocJig.Alias] like labels:
+//
+//	jig.Alias("postgres", "psql", "pgsql")
+var defaultLanguageAliases = [][]string{
+	{"sh", "bash", "shell", "console", "zsh", "shell-session"},
+	{"js", "javascript"},
+	{"ts", "typescript"},
+	{"py", "python", "python3"},
+	{"rb", "ruby"},
+	{"go", "golang"},
+	{"yaml", "yml"},
+	{"md", "markdown"},
+	{"ps1", "powershell", "pwsh"},
+	{"cs", "csharp", "c#"},
+	{"cpp", "c++"},
+	{"kt", "kotlin"},
+	{"rs", "rust"},
+}
+
+// GuessLanguage enables language detection for code fences without language
+//
+// The guessed language is used to select the code fence binding and is stored into language field.
+// If guess is not specified, [GuessLanguage] is used.
+func (j *DocJig[T]) GuessLanguage(guess ...func(code string) string) {
+	j.invalidate()
+	j.guessLanguage = GuessLanguage
+	if len(guess) > 0 {
+		j.guessLanguage = guess[0]
+	}
+}
+
+// GuessLanguage guesses language of code. It returns "" if it can't detect.
+//
+// It checks shebang line (#!/usr/bin/env python) and JSON syntax, then uses
+// analysers of chroma lexers (Go, shell scripts and so on).
+func GuessLanguage(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	if strings.HasPrefix(code, "#!") {
+		line, _, _ := strings.Cut(code, "\n")
+		fields := strings.Fields(line[2:])
+		if len(fields) > 0 {
+			interpreter := path.Base(fields[0])
+			if interpreter == "env" && len(fields) > 1 {
+				interpreter = fields[1]
+			}
+			if l := lexers.Get(interpreter); l != nil {
+				return languageName(l.Config().Aliases, l.Config().Name)
+			}
+		}
+	}
+	if (strings.HasPrefix(code, "{") || strings.HasPrefix(code, "[")) && json.Valid([]byte(code)) {
+		return "json"
+	}
+	if l := lexers.Analyse(code); l != nil {
+		return languageName(l.Config().Aliases, l.Config().Name)
+	}
+	return ""
+}
+
+func languageName(aliases []string, name string) string {
+	if len(aliases) > 0 {
+		return aliases[0]
+	}
+	return strings.ToLower(name)
+}
+
+// defaultLanguageIndex is lower-cased language to primary language map of defaultLanguageAliases
+var defaultLanguageIndex = func() map[string]string {
+	result := make(map[string]string)
+	for _, group := range defaultLanguageAliases {
+		for _, l := range group {
+			result[strings.ToLower(l)] = group[0]
+		}
+	}
+	return result
+}()
+
+// canonicalLanguage returns primary language of lang. Aliases registered by [DocJig.Alias] are
+// translated before the default aliases.
+func (j *DocJig[T]) canonicalLanguage(lang string) string {
+	ll := strings.ToLower(lang)
+	if p, ok := j.aliasIndex[ll]; ok {
+		ll = strings.ToLower(p)
+	}
+	if p, ok := defaultLanguageIndex[ll]; ok {
+		return p
+	}
+	return ll
+}
@@ -0,0 +1,109 @@
+package mdd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageAlias(t *testing.T) {
+	type Doc struct {
+		Script string
+		Lang   string
+		SQL    string
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Alias("postgres", "psql", "PgSQL")
+	jig.Root().CodeFence("Script", "bash").Language("Lang")
+	jig.Root().CodeFence("SQL", "psql")
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Doc
+
+	~~~Console
+	$ go test ./...
+	~~~
+
+	~~~pgsql
+	select 1;
+	~~~
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{
+		Script: "$ go test ./...",
+		Lang:   "Console",
+		SQL:    "select 1;",
+	}, got)
+}
+
+func TestGuessLanguage(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "package main\n\nfunc main() {}\n", want: "go"},
+		{code: "#!/usr/bin/env python\nprint(1)\n", want: "python"},
+		{code: "#!/bin/bash\necho hello\n", want: "bash"},
+		{code: `{"a": [1, 2]}`, want: "json"},
+		{code: "hello world", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, GuessLanguage(tc.code))
+		})
+	}
+}
+
+func TestDocJig_GuessLanguage(t *testing.T) {
+	type Doc struct {
+		Go     string
+		Script string
+		Lang   string
+		Other  string
+	}
+
+	src := TrimIndent(t, `
+	# Doc
+
+	~~~
+	package main
+	~~~
+
+	~~~
+	#!/bin/sh
+	echo hello
+	~~~
+
+	~~~
+	hello
+	~~~
+	`)
+
+	t.Run("guess", func(t *testing.T) {
+		jig := NewDocJig[Doc]()
+		jig.GuessLanguage()
+		jig.Root().CodeFence("Go", "go")
+		jig.Root().CodeFence("Script", "sh").Language("Lang")
+		jig.Root().CodeFence("Other")
+		got, err := jig.ParseString(src)
+		assert.NoError(t, err)
+		assert.Equal(t, &Doc{
+			Go:     "package main",
+			Script: "#!/bin/sh\necho hello",
+			Lang:   "bash",
+			Other:  "hello",
+		}, got)
+	})
+
+	t.Run("custom guesser", func(t *testing.T) {
+		jig := NewDocJig[Doc]()
+		jig.GuessLanguage(func(code string) string {
+			return "go"
+		})
+		jig.Root().CodeFence("Go", "go").Merge(MergeLast)
+		got, err := jig.ParseString(src)
+		assert.NoError(t, err)
+		assert.Equal(t, &Doc{Go: "hello"}, got)
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
-
-	"github.com/future-architect/tagscanner/runtimescan"
 )
 
 // Layout[T] represents document structure
@@ -33,6 +31,12 @@ type Layout[T any] struct {
 	table             *Table[T]
 	repeat            bool
 	options           []*Option[T]
+
+	labelMerge merge
+
+	// compiled plan
+	labelRef    fieldRef
+	instanceRef fieldRef
 }
 
 func (l *Layout[T]) Sample(sample string, samples ...string) *Layout[T] {
@@ -51,6 +55,7 @@ func (l *Layout[T]) ID(labelID string) *Layout[T] {
 }
 
 func (l *Layout[T]) Label(fieldName string, pattern ...string) *Layout[T] {
+	l.j.invalidate()
 	l.labelFieldName = fieldName
 	if len(pattern) > 0 {
 		l.labelPattern = pattern[0]
@@ -58,30 +63,76 @@ func (l *Layout[T]) Label(fieldName string, pattern ...string) *Layout[T] {
 	return l
 }
 
+// MergeLabel specifies the behavior when the label field is filled twice (default is [MergeError])
+//
+// It happens when the same "." section appears multiple times.
+func (l *Layout[T]) MergeLabel(policy MergePolicy, separator ...string) *Layout[T] {
+	l.j.invalidate()
+	l.labelMerge = newMerge(policy, separator)
+	return l
+}
+
+// Child adds a layer for the section whose heading matches pattern to the field
+//
+// Calling it again with the same field and pattern returns the existing layer to refine it
+// (e.g. jig from [NewDocJigFromTags]).
 func (l *Layout[T]) Child(instanceFieldName string, pattern ...string) *Layout[T] {
+	return l.child(instanceFieldName, pattern, false)
+}
+
+// Children is same as [Layout.Child] but the field receives every matching section
+//
+// Calling it again with the same field and pattern returns the existing layer. Child and Children
+// definitions are not shared even if they have same field and pattern.
+func (l *Layout[T]) Children(instanceFieldName string, pattern ...string) *Layout[T] {
+	return l.child(instanceFieldName, pattern, true)
+}
+
+func (l *Layout[T]) child(instanceFieldName string, pattern []string, repeat bool) *Layout[T] {
 	if l.Level == 6 {
 		panic("Level should be under 7")
 	}
+	var p string
+	if len(pattern) > 0 {
+		p = pattern[0]
+	}
+	for _, c := range l.children {
+		if c.instanceFieldName == instanceFieldName && c.labelPattern == p && c.repeat == repeat {
+			return c // refine existing definition
+		}
+	}
+	l.j.invalidate()
 	child := &Layout[T]{
 		j:                 l.j,
 		Level:             l.Level + 1,
 		instanceFieldName: instanceFieldName,
+		labelPattern:      p,
+		repeat:            repeat,
 	}
 	l.children = append(l.children, child)
-	if len(pattern) > 0 {
-		child.labelPattern = pattern[0]
-	}
 	return child
 }
 
-func (l *Layout[T]) Children(instanceFieldName string, pattern ...string) *Layout[T] {
-	children := l.Child(instanceFieldName, pattern...)
-	children.repeat = true
-	return children
-}
-
+// CodeFence binds code fences of targetLanguages (all if empty) to the field
+//
+// A string (or convertible) field receives one fence. []string and [][Snippet] fields receive
+// every matching fence in order, and map[string]string field is keyed by the info string (```go:main.go).
+//
+// Calling it again with the same field and languages returns the existing binding to refine it.
+// Other languages (or no languages) add another binding to the same field.
 func (l *Layout[T]) CodeFence(fieldName string, targetLanguages ...string) *CodeFence[T] {
+	for _, cf := range l.codeFences {
+		bound := cf.targetLanguages
+		if cf.defaultLanguages {
+			bound = nil
+		}
+		if cf.fieldName == fieldName && sameStrings(bound, targetLanguages) {
+			return cf // refine existing definition
+		}
+	}
+	l.j.invalidate()
 	cf := &CodeFence[T]{
+		j:               l.j,
 		fieldName:       fieldName,
 		targetLanguages: targetLanguages,
 		repeat:          l.repeat,
@@ -92,8 +143,12 @@ func (l *Layout[T]) CodeFence(fieldName string, targetLanguages ...string) *Code
 
 func (l *Layout[T]) Table(fieldName string) *Table[T] {
 	if l.table != nil {
+		if l.table.fieldName == fieldName {
+			return l.table // refine existing definition
+		}
 		panic("this layout has table already")
 	}
+	l.j.invalidate()
 	l.table = &Table[T]{
 		j:         l.j,
 		fieldName: fieldName,
@@ -101,16 +156,26 @@ func (l *Layout[T]) Table(fieldName string) *Table[T] {
 	return l.table
 }
 
+// Option binds the list item that starts with pattern (field name if empty) to the field
+//
+// Calling it again with the same field and pattern returns the existing binding to refine it.
+// Other patterns add another binding to the same field.
 func (l *Layout[T]) Option(fieldName string, pattern ...string) *Option[T] {
+	p := lastName(fieldName)
+	if len(pattern) > 0 {
+		p = pattern[0]
+	}
+	for _, o := range l.options {
+		if o.fieldName == fieldName && o.pattern == p {
+			return o // refine existing definition
+		}
+	}
+	l.j.invalidate()
 	result := &Option[T]{
 		j:         l.j,
 		l:         l,
 		fieldName: fieldName,
-	}
-	if len(pattern) > 0 {
-		result.pattern = pattern[0]
-	} else {
-		result.pattern = fieldName
+		pattern:   p,
 	}
 	l.options = append(l.options, result)
 	return result
@@ -123,7 +188,7 @@ func (l *Layout[T]) findMatchedChild(label string, parentValue reflect.Value) (c
 			if c.instanceFieldName == "." {
 				childValue = parentValue
 			} else {
-				childValue = parentValue.FieldByName(c.instanceFieldName)
+				childValue = c.instanceRef.field(parentValue)
 			}
 			if !childValue.IsValid() {
 				return nil, reflect.Value{}, "", false, fmt.Errorf("%s should have field %s but not", parentValue.Type(), c.instanceFieldName)
@@ -153,8 +218,11 @@ func (l *Layout[T]) findMatchedChild(label string, parentValue reflect.Value) (c
 	return nil, reflect.Value{}, "", false, nil
 }
 
-func (l *Layout[T]) findMatchedCodeFence(lang string) (cf *CodeFence[T], ok bool) {
+func (l *Layout[T]) findMatchedCodeFence(lang string, fenced bool) (cf *CodeFence[T], ok bool) {
 	for _, c := range l.codeFences {
+		if c.ignoreIndented && !fenced {
+			continue
+		}
 		if c.matchLanguage(lang) {
 			return c, true
 		}
@@ -171,23 +239,21 @@ func (l *Layout[T]) processOption(label string, target reflect.Value) (string, e
 	}
 	for _, opt := range strings.Split(result[2], ",") {
 		opt := strings.TrimSpace(opt)
-		var key string
-		var value any
-		var ok bool
-		if key, value, ok = strings.Cut(opt, "="); ok {
+		key, value, ok := strings.Cut(opt, "=")
+		if ok {
 			key = strings.TrimSpace(key)
-			value = strings.TrimSpace(value.(string))
+			value = strings.TrimSpace(value)
 		} else {
 			key = opt
-			value = true
+			value = "true"
 		}
 		for _, o := range l.options {
 			if o.pattern == key {
-				f := target.FieldByName(key)
+				f := o.ref.field(target)
 				if !f.IsValid() {
-					return "", fmt.Errorf("%s should have field %s but not", target.Type(), o.fieldName)
+					return "", fmt.Errorf("%s should have field %s but not", reflect.Indirect(target).Type(), o.fieldName)
 				}
-				err := runtimescan.FuzzyAssign(f.Addr().Interface(), value)
+				err := assignValue(target, o.ref, value, o.merge, "option", strings.TrimSpace(result[1]))
 				if err != nil {
 					return "", err
 				}
@@ -204,6 +270,27 @@ type Option[T any] struct {
 	fieldName string
 	pattern   string
 	sample    any
+	bools     *boolVocabulary
+	merge     merge
+	ref       fieldRef
+}
+
+// Merge specifies the behavior when the option field is filled twice (default is [MergeError])
+func (o *Option[T]) Merge(policy MergePolicy, separator ...string) *Option[T] {
+	o.j.invalidate()
+	o.merge = newMerge(policy, separator)
+	return o
+}
+
+// Bool returns [BoolWords] only for this option. It replaces jig level words.
+func (o *Option[T]) Bool() *BoolWords {
+	if o.bools == nil {
+		o.bools = newBoolVocabulary(o.j.invalidate)
+	}
+	return &BoolWords{
+		vocab: o.bools,
+		lang:  o.j.DefaultLang,
+	}
 }
 
 func (o *Option[T]) Sample(s any) {
@@ -234,7 +321,7 @@ func (l Layout[T]) generateTemplate(w io.Writer, lang string) error {
 		}
 
 		for _, cf := range l.codeFences {
-			cf.generateTemplate(w)
+			cf.generateTemplate(w, lang)
 		}
 
 		if l.table != nil {
@@ -282,9 +369,9 @@ func (l Layout[T]) templateLabel(i int, lang string) string {
 	for _, o := range l.options {
 		if o.sample != nil {
 			if o.sample == true {
-				opts = append(opts, i18n(o.fieldName))
+				opts = append(opts, i18n(o.pattern))
 			} else {
-				opts = append(opts, fmt.Sprintf("%s=[%v]", i18n(o.fieldName), o.sample))
+				opts = append(opts, fmt.Sprintf("%s=[%v]", i18n(o.pattern), o.sample))
 			}
 		}
 	}
@@ -449,6 +449,190 @@ func TestLayout_HeadingOption(t *testing.T) {
 	}
 }
 
+func TestLayout_Rebind(t *testing.T) {
+	type Row struct {
+		Name string
+		Desc string
+	}
+
+	type Doc struct {
+		Code  string
+		Limit int
+		Rows  []Row
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "code fences of other languages",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().CodeFence("Code", "sql")
+					jig.Root().CodeFence("Code", "go")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root
+
+				~~~sql
+				select 1;
+				~~~
+				`),
+			},
+			want: &Doc{
+				Code: "select 1;",
+			},
+		},
+		{
+			name: "options of other patterns",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().Option("Limit", "limit")
+					jig.Root().Option("Limit", "max")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root (limit=10)
+				`),
+			},
+			want: &Doc{
+				Limit: 10,
+			},
+		},
+		{
+			name: "table fields of other keys",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows")
+					table.Field("Name")
+					table.Field("Desc", "Description")
+					table.Field("Desc", "Detail")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root
+
+				| Name  | Description |
+				|-------|-------------|
+				| users | user table  |
+				`),
+			},
+			want: &Doc{
+				Rows: []Row{
+					{Name: "users", Desc: "user table"},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLayout_Refine(t *testing.T) {
+	type Doc struct {
+		Code     string
+		Safe     bool
+		Children []Doc
+	}
+
+	jig := NewDocJig[Doc]()
+	root := jig.Root()
+
+	cf := root.CodeFence("Code", "sql")
+	assert.Same(t, cf, root.CodeFence("Code", "sql"))
+	assert.NotSame(t, cf, root.CodeFence("Code"))
+	assert.NotSame(t, cf, root.CodeFence("Code", "go"))
+	assert.Same(t, root.CodeFence("Code"), root.CodeFence("Code"))
+
+	opt := root.Option("Safe", "safe")
+	assert.Same(t, opt, root.Option("Safe", "safe"))
+	assert.NotSame(t, opt, root.Option("Safe"))
+	assert.NotSame(t, opt, root.Option("Safe", "secure"))
+	assert.Same(t, root.Option("Safe"), root.Option("Safe", "Safe"))
+
+	child := root.Child("Children", "Child")
+	assert.Same(t, child, root.Child("Children", "Child"))
+	assert.NotSame(t, child, root.Child("Children", "Other"))
+	children := root.Children("Children", "Child")
+	assert.NotSame(t, child, children)
+	assert.False(t, child.repeat)
+	assert.Same(t, children, root.Children("Children", "Child"))
+
+	table := root.Table("Rows")
+	f := table.Field("Desc", "Description")
+	assert.NotSame(t, f, table.Field("Desc"))
+	assert.Same(t, f, table.Field("Desc", "Description"))
+	assert.NotSame(t, f, table.Field("Desc", "Detail"))
+
+	data := root.CodeFence("Data")
+	data.CSV()
+	assert.Same(t, data, root.CodeFence("Data"))
+	assert.NotSame(t, data, root.CodeFence("Data", "csv"))
+}
+
+func TestLayout_RefineBindsOtherKeys(t *testing.T) {
+	type Row struct {
+		Name string
+	}
+	type Doc struct {
+		Code string
+		Rows []Row
+	}
+
+	jig := NewDocJig[Doc]()
+	root := jig.Root()
+	root.CodeFence("Code", "go")
+	root.CodeFence("Code")
+	table := root.Table("Rows")
+	table.Field("Name", "Title")
+	table.Field("Name")
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	~~~python
+	print("hello")
+	~~~
+
+	| Title |
+	|-------|
+	| one   |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{Code: `print("hello")`, Rows: []Row{{Name: "one"}}}, got)
+
+	got, err = jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	| Name |
+	|------|
+	| two  |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{Rows: []Row{{Name: "two"}}}, got)
+}
+
 func TestLayout_Generate(t *testing.T) {
 	type Level2 struct {
 		Name      string
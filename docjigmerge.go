@@ -0,0 +1,106 @@
+package mdd
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergePolicy specifies behavior when the same field is filled twice
+//
+// It happens when the same "." section appears multiple times, or when several code fences
+// match one binding.
+type MergePolicy int
+
+const (
+	// MergeError reports error (default except tables)
+	MergeError MergePolicy = iota
+	// MergeAppend appends values to slice field (default of tables)
+	MergeAppend
+	// MergeConcat concatenates strings with separator
+	MergeConcat
+	// MergeFirst keeps the first value
+	MergeFirst
+	// MergeLast overwrites by the last value
+	MergeLast
+)
+
+type merge struct {
+	policy    MergePolicy
+	separator string
+}
+
+func newMerge(policy MergePolicy, separator []string) merge {
+	m := merge{policy: policy, separator: "\n"}
+	if len(separator) > 0 {
+		m.separator = separator[0]
+	}
+	return m
+}
+
+// sub returns policy for the sub fields (code fence's language and info)
+func (m merge) sub(t reflect.Type) merge {
+	switch {
+	case m.policy == MergeAppend && t != nil && t.Kind() == reflect.Slice:
+		return m
+	case m.policy == MergeError || m.policy == MergeLast:
+		return m
+	}
+	return merge{policy: MergeFirst}
+}
+
+// appendSetter returns setter that appends a value to slice field of type t.
+func (j *DocJig[T]) appendSetter(t reflect.Type, bools *boolVocabulary) stringSetter {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() != reflect.Slice {
+		return func(field reflect.Value, value string) error {
+			return fmt.Errorf("field to append should be slice, but %s", t)
+		}
+	}
+	set := j.setter(t.Elem(), bools)
+	return func(field reflect.Value, value string) error {
+		v := reflect.New(t.Elem()).Elem()
+		if err := set(v, value); err != nil {
+			return err
+		}
+		field.Set(reflect.Append(field, v))
+		return nil
+	}
+}
+
+// resolveMerge is a variation of resolve for bindings that have merge policy
+func (j *DocJig[T]) resolveMerge(t reflect.Type, name string, bools *boolVocabulary, m merge) fieldRef {
+	r := j.resolve(t, name, bools)
+	if m.policy == MergeAppend {
+		r.set = j.appendSetter(r.typ(t), bools)
+	}
+	return r
+}
+
+func assignValue(target reflect.Value, ref fieldRef, value string, m merge, context, label string) error {
+	if ref.name == "" {
+		return nil
+	}
+	field := ref.field(target)
+	if !field.IsValid() {
+		return fmt.Errorf("%s doesn't have field '%s' for %s (inside '%s' section)", target.Type(), ref.name, context, label)
+	}
+	if m.policy != MergeAppend && !field.IsZero() {
+		switch m.policy {
+		case MergeFirst:
+			return nil
+		case MergeConcat:
+			if field.Kind() != reflect.String {
+				return fmt.Errorf("field '%s' for %s should be string to concatenate, but %s (inside '%s' section)", ref.name, context, field.Type(), label)
+			}
+			field.SetString(field.String() + m.separator + value)
+			return nil
+		case MergeLast:
+			field.Set(reflect.Zero(field.Type()))
+		default:
+			return fmt.Errorf("field '%s' for %s is already filled (inside '%s' section)", ref.name, context, label)
+		}
+	}
+	return ref.set(field, value)
+}
@@ -0,0 +1,200 @@
+package mdd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePolicy(t *testing.T) {
+	type Row struct {
+		Name string
+	}
+
+	type Doc struct {
+		Title   string
+		Titles  []string
+		SQL     string
+		SQLs    []string
+		Lang    string
+		Langs   []string
+		Limit   int
+		Rows    []Row
+		Records []map[string]string
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+
+	src := TrimIndent(t, `
+	# Root Heading
+
+	## Query: first (Limit=10)
+
+	~~~sql
+	select 1;
+	~~~
+
+	| Name |
+	|------|
+	| a    |
+
+	## Query: second (Limit=20)
+
+	~~~psql
+	select 2;
+	~~~
+
+	| Name |
+	|------|
+	| b    |
+	`)
+
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "default: error",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.CodeFence("SQL")
+					return jig
+				},
+				src: src,
+			},
+			wantErr: "field 'SQL' for code fence is already filled (inside 'second' section)",
+		},
+		{
+			name: "default of table: append",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.Table("Rows")
+					child.Table("Rows").Field("Name")
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				Rows: []Row{{Name: "a"}, {Name: "b"}},
+			},
+		},
+		{
+			name: "append and concat",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.Label("Titles").MergeLabel(MergeAppend)
+					child.CodeFence("SQL").Language("Lang").Merge(MergeConcat, "\n\n")
+					child.Table("Records").AsMap()
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				Titles:  []string{"first", "second"},
+				SQL:     "select 1;\n\nselect 2;",
+				Lang:    "sql",
+				Records: []map[string]string{{"Name": "a"}, {"Name": "b"}},
+			},
+		},
+		{
+			name: "append fences to slice",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.CodeFence("SQLs").Language("Langs").Merge(MergeAppend)
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				SQLs:  []string{"select 1;", "select 2;"},
+				Langs: []string{"sql", "psql"},
+			},
+		},
+		{
+			name: "first and last",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.Label("Title").MergeLabel(MergeFirst)
+					child.Option("Limit").Merge(MergeLast)
+					child.CodeFence("SQL").Language("Lang").Merge(MergeLast)
+					child.Table("Rows").Merge(MergeLast).Field("Name")
+					return jig
+				},
+				src: src,
+			},
+			want: &Doc{
+				Title: "first",
+				Limit: 20,
+				SQL:   "select 2;",
+				Lang:  "psql",
+				Rows:  []Row{{Name: "b"}},
+			},
+		},
+		{
+			name: "table error",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.Table("Rows").Merge(MergeError).Field("Name")
+					return jig
+				},
+				src: src,
+			},
+			wantErr: "field 'Rows' for table is already filled (inside 'second' section)",
+		},
+		{
+			name: "option error",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.Option("Limit")
+					return jig
+				},
+				src: src,
+			},
+			wantErr: "field 'Limit' for option is already filled (inside 'second' section)",
+		},
+		{
+			name: "concat requires string",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					child := jig.Root().Children(".", "Query")
+					child.Option("Limit").Merge(MergeConcat)
+					return jig
+				},
+				src: src,
+			},
+			wantErr: "field 'Limit' for option should be string to concatenate, but int (inside 'second' section)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package mdd
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+// fieldRef is a reference to a struct field that is resolved when
+// the jig is compiled.
+//
+// Parsing uses the stored index instead of calling reflect.Value.FieldByName
+// for every heading, code fence and table cell.
+//
+// name can be dotted path like "Meta.Author" to refer a field of nested struct.
+type fieldRef struct {
+	name  string
+	path  [][]int // field index of each path element
+	valid bool
+	set   stringSetter
+}
+
+func resolveField(t reflect.Type, name string) fieldRef {
+	r := fieldRef{name: name}
+	if name == "" || t == nil {
+		return r
+	}
+	for _, n := range strings.Split(name, ".") {
+		t = indirectType(t)
+		if t.Kind() != reflect.Struct {
+			return r
+		}
+		f, ok := t.FieldByName(n)
+		if !ok {
+			return r
+		}
+		r.path = append(r.path, f.Index)
+		t = f.Type
+	}
+	r.valid = true
+	return r
+}
+
+// field returns the field of target. target can be a pointer of struct.
+//
+// Nil pointers of nested structs in dotted path are allocated.
+// It returns invalid value if the field doesn't exist.
+func (r fieldRef) field(target reflect.Value) reflect.Value {
+	if !r.valid {
+		return reflect.Value{}
+	}
+	if len(r.path) == 0 {
+		return reflect.Indirect(target)
+	}
+	v := target
+	for _, index := range r.path {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		f, err := v.FieldByIndexErr(index)
+		if err != nil {
+			return reflect.Value{}
+		}
+		v = f
+	}
+	return v
+}
+
+// lastName returns the last element of dotted field path. It is used as default column key and option pattern.
+func lastName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r fieldRef) typ(t reflect.Type) reflect.Type {
+	if !r.valid {
+		return nil
+	}
+	for _, index := range r.path {
+		t = indirectType(t).FieldByIndex(index).Type
+	}
+	return t
+}
+
+// stringSetter is a converter that stores text (label, option, code and cell) into a field.
+type stringSetter func(field reflect.Value, value string) error
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// newStringSetter selects converter for the field type once.
+//
+// Priority is: registered converter, encoding.TextUnmarshaler, pointer (allocates value),
+// then the same behavior as runtimescan.FuzzyAssign when value is string except bool
+// that uses the word table (see [BoolWords]).
+func newStringSetter(t reflect.Type, converters map[reflect.Type]func(string) (any, error), bools map[string]bool) stringSetter {
+	if t == nil {
+		return nil
+	}
+	if convert, ok := converters[t]; ok {
+		return func(field reflect.Value, value string) error {
+			v, err := convert(value)
+			if err != nil {
+				return err
+			}
+			return setConverted(field, v)
+		}
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return func(field reflect.Value, value string) error {
+			return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+		}
+	}
+	if t.Kind() == reflect.Pointer {
+		elemSetter := newStringSetter(t.Elem(), converters, bools)
+		return func(field reflect.Value, value string) error {
+			v := reflect.New(t.Elem())
+			if err := elemSetter(v.Elem(), value); err != nil {
+				return err
+			}
+			field.Set(v)
+			return nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return func(field reflect.Value, value string) error {
+			field.SetString(value)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(field reflect.Value, value string) error {
+			v, err := strconv.ParseInt(value, 10, t.Bits()) // errors if it overflows the field
+			if err != nil {
+				return err
+			}
+			field.SetInt(v)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(field reflect.Value, value string) error {
+			v, err := strconv.ParseUint(value, 10, t.Bits())
+			if err != nil {
+				return err
+			}
+			field.SetUint(v)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(field reflect.Value, value string) error {
+			v, err := strconv.ParseFloat(value, t.Bits())
+			if err != nil {
+				return err
+			}
+			field.SetFloat(v)
+			return nil
+		}
+	case reflect.Bool:
+		return func(field reflect.Value, value string) error {
+			b, err := parseBool(bools, value)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+			return nil
+		}
+	}
+	return func(field reflect.Value, value string) error {
+		return runtimescan.FuzzyAssign(field.Addr().Interface(), value)
+	}
+}
+
+// setConverted stores the result of converter into a field with type checking
+func setConverted(field reflect.Value, value any) error {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case v.Kind() == reflect.Pointer && v.Type().Elem().AssignableTo(field.Type()):
+		if !v.IsNil() {
+			field.Set(v.Elem())
+		}
+	case field.Kind() == reflect.Pointer && v.Type().AssignableTo(field.Type().Elem()):
+		p := reflect.New(field.Type().Elem())
+		p.Elem().Set(v)
+		field.Set(p)
+	default:
+		return fmt.Errorf("converted value type %s is not assignable to %s", v.Type(), field.Type())
+	}
+	return nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// compile resolves field references and label tables of all layouts.
+//
+// It is called at the first parse after the definition is modified.
+func (j *DocJig[T]) compile() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.compiled {
+		return
+	}
+	j.boolMap = j.bools.table(defaultBoolWords)
+	j.compileAliasIndex()
+	j.root.compile(j.rootType())
+	j.compiled = true
+}
+
+// resolve resolves field reference with the setter for its type.
+//
+// bools is the field's own boolean words. If it is nil, jig level words are used.
+func (j *DocJig[T]) resolve(t reflect.Type, name string, bools *boolVocabulary) fieldRef {
+	r := resolveField(t, name)
+	r.set = j.setter(r.typ(t), bools)
+	return r
+}
+
+// setter returns the setter for type t with jig's converters and boolean words.
+func (j *DocJig[T]) setter(t reflect.Type, bools *boolVocabulary) stringSetter {
+	boolMap := j.boolMap
+	if bools != nil {
+		boolMap = bools.table(minimumBoolWords)
+	}
+	return newStringSetter(t, j.converters, boolMap)
+}
+
+// invalidate discards the compiled plan. It is called by the definition methods.
+func (j *DocJig[T]) invalidate() {
+	j.mu.Lock()
+	j.compiled = false
+	j.mu.Unlock()
+}
+
+func (l *Layout[T]) compile(t reflect.Type) {
+	l.labelRef = l.j.resolveMerge(t, l.labelFieldName, nil, l.labelMerge)
+	for _, o := range l.options {
+		o.ref = l.j.resolveMerge(t, o.fieldName, o.bools, o.merge)
+	}
+	for _, cf := range l.codeFences {
+		cf.compile(t)
+	}
+	if l.table != nil {
+		l.table.compile(t)
+	}
+	for _, c := range l.children {
+		var ct reflect.Type
+		if c.instanceFieldName == "." {
+			c.instanceRef = fieldRef{name: ".", valid: true}
+			ct = t
+		} else {
+			c.instanceRef = resolveField(t, c.instanceFieldName)
+			if ft := c.instanceRef.typ(t); ft != nil {
+				ft = indirectType(ft)
+				if c.repeat && ft.Kind() == reflect.Slice {
+					ft = indirectType(ft.Elem())
+				}
+				ct = ft
+			}
+		}
+		c.compile(ct)
+	}
+}
+
+func (t *Table[T]) compile(target reflect.Type) {
+	t.ref = resolveField(target, t.fieldName)
+	if t.fieldName == "." {
+		t.ref = fieldRef{name: ".", valid: true}
+	}
+	t.rowType = nil
+	t.rowPtr = false
+	if st := t.ref.typ(target); st != nil {
+		switch st.Kind() {
+		case reflect.Struct, reflect.Pointer:
+			if t.transpose {
+				t.rowType = indirectType(st)
+			}
+		case reflect.Slice:
+			t.rowType = st.Elem()
+		case reflect.Map:
+			t.rowType = st.Elem()
+			if t.rowType.Kind() == reflect.Pointer {
+				t.rowType = t.rowType.Elem()
+				t.rowPtr = true
+			}
+		}
+	}
+
+	// Column name (lower cased) to field index.
+	// Aliases have priority over primary keys of other fields.
+	t.columns = make(map[string]int)
+	for i, f := range t.fields {
+		for _, a := range t.j.aliases[f.key] {
+			if _, ok := t.columns[a.lowLabel]; !ok {
+				t.columns[a.lowLabel] = i
+			}
+		}
+	}
+	for i, f := range t.fields {
+		if _, ok := t.columns[f.key]; !ok {
+			t.columns[f.key] = i
+		}
+		f.ref = t.j.resolve(t.rowType, f.fieldName, f.bools)
+		if f.split != nil {
+			f.ref.set = f.split.setter(f.ref.typ(t.rowType), func(et reflect.Type) stringSetter {
+				return t.j.setter(et, f.bools)
+			})
+		}
+		// blank cells of bool fields are converted by the vocabulary
+		ft := f.ref.typ(t.rowType)
+		f.blankBool = f.split == nil && ft != nil && ft.Kind() == reflect.Bool
+	}
+	for _, item := range t.items {
+		if item.group != nil {
+			item.group.compile(t.rowType)
+		}
+	}
+	t.extraRef = resolveField(t.rowType, t.extra)
+}
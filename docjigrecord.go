@@ -0,0 +1,166 @@
+package mdd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Record is a table row that keeps column order
+//
+// It is an alternative target of [Table.AsMap] ([]Record or map[string]Record with [Table.KeyBy])
+// for re-rendering or exporting tables as authors wrote.
+type Record struct {
+	keys   *recordKeys
+	values []string
+}
+
+var recordType = reflect.TypeOf(Record{})
+
+// recordKeys is shared by all records in one table
+type recordKeys struct {
+	headers  []string // primary keys
+	original []string // header texts in the document
+	index    map[string]int
+}
+
+// Headers returns column names in column order. Aliases are translated into primary keys.
+func (r Record) Headers() []string {
+	if r.keys == nil {
+		return nil
+	}
+	return r.keys.headers
+}
+
+// OriginalHeaders returns column names as written in the document
+func (r Record) OriginalHeaders() []string {
+	if r.keys == nil {
+		return nil
+	}
+	return r.keys.original
+}
+
+// Values returns cell values in column order
+func (r Record) Values() []string {
+	return r.values
+}
+
+// Len returns column count
+func (r Record) Len() int {
+	return len(r.values)
+}
+
+// Get returns the value of the column. key is case-insensitive and it can be primary key, alias or original header.
+func (r Record) Get(key string) (string, bool) {
+	if r.keys == nil {
+		return "", false
+	}
+	if i, ok := r.keys.index[strings.ToLower(key)]; ok {
+		return r.values[i], true
+	}
+	return "", false
+}
+
+// Value is a variation of [Record.Get] that returns empty string for missing column
+func (r Record) Value(key string) string {
+	v, _ := r.Get(key)
+	return v
+}
+
+// Map converts record into map (same as the row of [Table.AsMap])
+func (r Record) Map() map[string]string {
+	result := make(map[string]string, len(r.values))
+	for i, h := range r.Headers() {
+		result[h] = r.values[i]
+	}
+	return result
+}
+
+// MarshalJSON writes record as JSON object that keeps column order
+func (r Record) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, h := range r.Headers() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// compileAliasIndex builds lower cased label to primary label table.
+//
+// Primary labels have priority over aliases. If an alias belongs to several primary labels,
+// the primary label that comes first in sort order wins so that the result doesn't depend on map order.
+func (j *DocJig[T]) compileAliasIndex() {
+	j.aliasIndex = make(map[string]string)
+	primaries := make([]string, 0, len(j.aliases))
+	for k := range j.aliases {
+		primaries = append(primaries, k)
+	}
+	sort.Strings(primaries)
+	for _, p := range primaries {
+		j.aliasIndex[p] = j.aliases[p][0].label
+	}
+	for _, p := range primaries {
+		for _, a := range j.aliases[p] {
+			if _, ok := j.aliasIndex[a.lowLabel]; !ok {
+				j.aliasIndex[a.lowLabel] = j.aliases[p][0].label
+			}
+		}
+	}
+}
+
+// newRecordKeys translates headers into primary keys.
+//
+// When several headers are translated into the same primary key, the first one uses it
+// and others keep their original spelling.
+func (j *DocJig[T]) newRecordKeys(headers []string) *recordKeys {
+	result := &recordKeys{
+		headers:  make([]string, len(headers)),
+		original: headers,
+		index:    make(map[string]int, len(headers)*2),
+	}
+	used := make(map[string]bool, len(headers))
+	for i, h := range headers {
+		key := h
+		if p, ok := j.aliasIndex[strings.ToLower(h)]; ok && !used[strings.ToLower(p)] {
+			key = p
+		}
+		used[strings.ToLower(key)] = true
+		result.headers[i] = key
+	}
+	// lookup: primary keys, original headers, then aliases
+	for i, h := range result.headers {
+		if _, ok := result.index[strings.ToLower(h)]; !ok {
+			result.index[strings.ToLower(h)] = i
+		}
+	}
+	for i, h := range headers {
+		if _, ok := result.index[strings.ToLower(h)]; !ok {
+			result.index[strings.ToLower(h)] = i
+		}
+	}
+	for i, h := range result.headers {
+		for _, a := range j.aliases[strings.ToLower(h)] {
+			if _, ok := result.index[a.lowLabel]; !ok {
+				result.index[a.lowLabel] = i
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package mdd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	type Doc struct {
+		Records []Record
+		Keyed   map[string]Record
+		Maps    []map[string]string
+	}
+
+	src := TrimIndent(t, `
+	# Root Heading
+
+	| Name | 型     | Description |
+	|------|--------|-------------|
+	| id   | int    | primary key |
+	| name | string |             |
+	`)
+
+	jig := NewDocJig[Doc]()
+	jig.Alias("Type", "型")
+	jig.Root().Table("Records").AsMap()
+
+	got, err := jig.ParseString(src)
+	assert.NoError(t, err)
+	if assert.Len(t, got.Records, 2) {
+		r := got.Records[0]
+		assert.Equal(t, []string{"Name", "Type", "Description"}, r.Headers())
+		assert.Equal(t, []string{"Name", "型", "Description"}, r.OriginalHeaders())
+		assert.Equal(t, []string{"id", "int", "primary key"}, r.Values())
+		assert.Equal(t, "int", r.Value("type"))
+		assert.Equal(t, "int", r.Value("型"))
+		_, ok := r.Get("Unknown")
+		assert.False(t, ok)
+		assert.Equal(t, map[string]string{"Name": "id", "Type": "int", "Description": "primary key"}, r.Map())
+
+		j, err := json.Marshal(r)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"Name":"id","Type":"int","Description":"primary key"}`, string(j))
+	}
+
+	keyed := NewDocJig[Doc]()
+	keyed.Root().Table("Keyed").AsMap().KeyBy("Name")
+	got, err = keyed.ParseString(src)
+	assert.NoError(t, err)
+	assert.Equal(t, "string", got.Keyed["name"].Value("型"))
+}
+
+func TestRecord_AliasCollision(t *testing.T) {
+	type Doc struct {
+		Maps []map[string]string
+	}
+
+	src := TrimIndent(t, `
+	# Root Heading
+
+	| Kind | Type | 種別 |
+	|------|------|------|
+	| a    | b    | c    |
+	`)
+
+	for i := 0; i < 10; i++ {
+		jig := NewDocJig[Doc]()
+		// "種別" is an alias of both "Kind" and "Type". Sorted first primary key ("Kind") wins.
+		jig.Alias("Type", "種別")
+		jig.Alias("Kind", "種別")
+		jig.Root().Table("Maps").AsMap()
+		got, err := jig.ParseString(src)
+		assert.NoError(t, err)
+		// "Kind" is already used by the first column, so "種別" keeps its spelling
+		assert.Equal(t, []map[string]string{{"Kind": "a", "Type": "b", "種別": "c"}}, got.Maps)
+
+		got, err = jig.ParseString("# Root Heading\n\n| ID | 種別 |\n|----|------|\n| 1  | a    |\n")
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]string{{"ID": "1", "Kind": "a"}}, got.Maps)
+	}
+}
@@ -0,0 +1,1076 @@
+package mdd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is a declarative definition of [DocJig]
+//
+// It mirrors builder methods of [DocJig] and [Layout]. It can be written in YAML or JSON:
+//
+//	aliases:
+//	  - label: CRUD Matrix
+//	    langs:
+//	      ja: [CRUDマトリックス]
+//	root:
+//	  label: Name
+//	  codeFences:
+//	    - field: SQL
+//	      languages: [sql]
+//	  children:
+//	    - field: .
+//	      pattern: CRUD Matrix
+//	      table:
+//	        field: CRUDMatrix
+//	        fields:
+//	          - field: Table
+//	            required: true
+//	          - field: R
+//	            type: bool
+//
+// Use [LoadSchema] to read and [NewDocJigFromSchema] to create parser.
+// [DocJig.Schema] exports existing jig into this format.
+type Schema struct {
+	DefaultLang string        `json:"defaultLang,omitempty" yaml:"defaultLang,omitempty"`
+	Aliases     []SchemaAlias `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	// Bool is jig level boolean words ([DocJig.Bool])
+	Bool []SchemaBool `json:"bool,omitempty" yaml:"bool,omitempty"`
+	// GuessLanguage enables [DocJig.GuessLanguage] with the default guesser
+	GuessLanguage bool         `json:"guessLanguage,omitempty" yaml:"guessLanguage,omitempty"`
+	Root          SchemaLayout `json:"root" yaml:"root"`
+}
+
+// SchemaAlias represents [DocJig.Alias] and [Alias.Lang]
+type SchemaAlias struct {
+	Label   string              `json:"label" yaml:"label"`
+	Aliases []string            `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Langs   map[string][]string `json:"langs,omitempty" yaml:"langs,omitempty"`
+}
+
+// SchemaBool represents [BoolWords] of the language (DefaultLang if empty)
+type SchemaBool struct {
+	Lang  string   `json:"lang,omitempty" yaml:"lang,omitempty"`
+	True  []string `json:"true,omitempty" yaml:"true,omitempty"`
+	False []string `json:"false,omitempty" yaml:"false,omitempty"`
+}
+
+// SchemaMerge represents [MergePolicy] and separator of merge methods
+//
+// Policy is "error", "append", "concat", "first" or "last". Fields with "append" are slices.
+// Separator is for "concat" (default is "\n").
+type SchemaMerge struct {
+	Policy    string  `json:"policy" yaml:"policy"`
+	Separator *string `json:"separator,omitempty" yaml:"separator,omitempty"`
+}
+
+// SchemaLayout represents [Layout]
+//
+// Field is instance field name of [Layout.Child] ("." keeps same struct). Repeat
+// is true when it is defined by [Layout.Children].
+type SchemaLayout struct {
+	Field          string            `json:"field,omitempty" yaml:"field,omitempty"`
+	Pattern        string            `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Repeat         bool              `json:"repeat,omitempty" yaml:"repeat,omitempty"`
+	Label          string            `json:"label,omitempty" yaml:"label,omitempty"`
+	LabelMerge     *SchemaMerge      `json:"labelMerge,omitempty" yaml:"labelMerge,omitempty"`
+	Samples        []string          `json:"samples,omitempty" yaml:"samples,omitempty"`
+	SampleContents []string          `json:"sampleContents,omitempty" yaml:"sampleContents,omitempty"`
+	Options        []SchemaOption    `json:"options,omitempty" yaml:"options,omitempty"`
+	CodeFences     []SchemaCodeFence `json:"codeFences,omitempty" yaml:"codeFences,omitempty"`
+	Table          *SchemaTable      `json:"table,omitempty" yaml:"table,omitempty"`
+	Children       []SchemaLayout    `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// SchemaOption represents [Layout.Option]
+type SchemaOption struct {
+	Field   string       `json:"field" yaml:"field"`
+	Pattern string       `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Type    string       `json:"type,omitempty" yaml:"type,omitempty"`
+	Sample  any          `json:"sample,omitempty" yaml:"sample,omitempty"`
+	Merge   *SchemaMerge `json:"merge,omitempty" yaml:"merge,omitempty"`
+	Bool    []SchemaBool `json:"bool,omitempty" yaml:"bool,omitempty"`
+}
+
+// SchemaCodeFence represents [Layout.CodeFence]
+type SchemaCodeFence struct {
+	Field      string   `json:"field" yaml:"field"`
+	Languages  []string `json:"languages,omitempty" yaml:"languages,omitempty"`
+	Language   string   `json:"language,omitempty" yaml:"language,omitempty"`
+	Info       string   `json:"info,omitempty" yaml:"info,omitempty"`
+	SampleCode string   `json:"sampleCode,omitempty" yaml:"sampleCode,omitempty"`
+	SampleInfo string   `json:"sampleInfo,omitempty" yaml:"sampleInfo,omitempty"`
+	// Attrs maps info string attribute names to fields. "highlight" field is []int.
+	Attrs map[string]string `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	// Type is "list" ([]string), "map" (map[string]string keyed by info) or "snippets" ([]Snippet)
+	// to collect every matching fence. Default is string.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Format is "csv", "tsv", "json" or "yaml" to decode the fence. Table has columns of CSV and TSV.
+	Format string       `json:"format,omitempty" yaml:"format,omitempty"`
+	Table  *SchemaTable `json:"table,omitempty" yaml:"table,omitempty"`
+	Merge  *SchemaMerge `json:"merge,omitempty" yaml:"merge,omitempty"`
+	// Validate is names of built-in validators: "json", "yaml", "go" and "sql"
+	Validate         []string `json:"validate,omitempty" yaml:"validate,omitempty"`
+	IgnoreIndented   bool     `json:"ignoreIndented,omitempty" yaml:"ignoreIndented,omitempty"`
+	KeepWhitespace   bool     `json:"keepWhitespace,omitempty" yaml:"keepWhitespace,omitempty"`
+	Dedent           bool     `json:"dedent,omitempty" yaml:"dedent,omitempty"`
+	NormalizeNewline bool     `json:"normalizeNewline,omitempty" yaml:"normalizeNewline,omitempty"`
+}
+
+// SchemaTable represents [Layout.Table]
+type SchemaTable struct {
+	Field string `json:"field" yaml:"field"`
+	AsMap bool   `json:"asMap,omitempty" yaml:"asMap,omitempty"`
+	KeyBy string `json:"keyBy,omitempty" yaml:"keyBy,omitempty"`
+	// Transpose is for vertical key/value table. Field "." means the section itself.
+	Transpose bool          `json:"transpose,omitempty" yaml:"transpose,omitempty"`
+	Fields    []SchemaField `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Extra is map[string]string field of undeclared columns
+	Extra  string `json:"extra,omitempty" yaml:"extra,omitempty"`
+	Strict bool   `json:"strict,omitempty" yaml:"strict,omitempty"`
+	// Merge is policy of tables stored into the same field (separator is not used)
+	Merge *SchemaMerge `json:"merge,omitempty" yaml:"merge,omitempty"`
+}
+
+// SchemaField represents [Table.Field] and [Table.FieldGroup]
+//
+// Group is columns of field group. Type of group is "" ([]string), "map" (map[string]bool) or "int" (bit flags).
+type SchemaField struct {
+	Field    string       `json:"field" yaml:"field"`
+	Key      string       `json:"key,omitempty" yaml:"key,omitempty"`
+	Group    []string     `json:"group,omitempty" yaml:"group,omitempty"`
+	Required bool         `json:"required,omitempty" yaml:"required,omitempty"`
+	Type     string       `json:"type,omitempty" yaml:"type,omitempty"`
+	Split    *SchemaSplit `json:"split,omitempty" yaml:"split,omitempty"`
+	Bool     []SchemaBool `json:"bool,omitempty" yaml:"bool,omitempty"`
+	Samples  []any        `json:"samples,omitempty" yaml:"samples,omitempty"`
+}
+
+// SchemaSplit represents [StructField.Split] and its options. The field is slice of Type.
+//
+// Separator is "," if both Separator and Regexp are empty.
+type SchemaSplit struct {
+	Separator string `json:"separator,omitempty" yaml:"separator,omitempty"`
+	Regexp    string `json:"regexp,omitempty" yaml:"regexp,omitempty"`
+	Trim      string `json:"trim,omitempty" yaml:"trim,omitempty"`
+	KeepSpace bool   `json:"keepSpace,omitempty" yaml:"keepSpace,omitempty"`
+}
+
+// LoadSchema reads [Schema] written in YAML or JSON
+func LoadSchema(r io.Reader) (*Schema, error) {
+	var s Schema
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	if err := d.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return &s, nil
+}
+
+// LoadSchemaFile reads [Schema] from file
+func LoadSchemaFile(filepath string) (*Schema, error) {
+	o, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer o.Close()
+	return LoadSchema(o)
+}
+
+// WriteYAML writes schema in YAML format
+func (s *Schema) WriteYAML(w io.Writer) error {
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(s); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// WriteJSON writes schema in JSON format
+func (s *Schema) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(s)
+}
+
+var schemaTypes = map[string]reflect.Type{
+	"":       reflect.TypeOf(""),
+	"string": reflect.TypeOf(""),
+	"int":    reflect.TypeOf(int(0)),
+	"float":  reflect.TypeOf(float64(0)),
+	"bool":   reflect.TypeOf(false),
+}
+
+func schemaTypeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	switch indirectType(t).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "bool"
+	}
+	return ""
+}
+
+// NewDocJigFromSchema creates untyped [DocJig] from [Schema]
+//
+// Parsed result is map[string]any (and []any, map[string]string for tables)
+// keyed by field names in schema. It can be dumped as JSON directly.
+func NewDocJigFromSchema(s *Schema) (*DocJig[map[string]any], error) {
+	docType, err := schemaStructType(s.Root)
+	if err != nil {
+		return nil, err
+	}
+	j := NewDocJig[map[string]any]()
+	if s.DefaultLang != "" {
+		j.DefaultLang = s.DefaultLang
+	}
+	j.docType = docType
+	j.fromDocType = func(v reflect.Value) any {
+		return toUntyped(v)
+	}
+	for _, a := range s.Aliases {
+		alias := j.Alias(a.Label, a.Aliases...)
+		langs := make([]string, 0, len(a.Langs))
+		for lang := range a.Langs {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			alias.Lang(lang, a.Langs[lang]...)
+		}
+	}
+	applySchemaBool(j.Bool(), s.Bool)
+	if s.GuessLanguage {
+		j.GuessLanguage()
+	}
+	if err := applySchemaLayout(j.root, s.Root); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func applySchemaBool(b *BoolWords, words []SchemaBool) {
+	for _, w := range words {
+		lb := b
+		if w.Lang != "" {
+			lb = b.Lang(w.Lang)
+		}
+		lb.True(w.True...)
+		lb.False(w.False...)
+	}
+}
+
+func applySchemaLayout[T any](l *Layout[T], s SchemaLayout) error {
+	if l.Level == 1 {
+		if s.Pattern != "" {
+			l.Label(s.Label, s.Pattern)
+		} else if s.Label != "" {
+			l.Label(s.Label)
+		}
+	} else if s.Label != "" {
+		l.Label(s.Label)
+	}
+	if s.LabelMerge != nil {
+		policy, sep, err := s.LabelMerge.merge("label")
+		if err != nil {
+			return err
+		}
+		l.MergeLabel(policy, sep...)
+	}
+	if len(s.Samples) > 0 {
+		l.Sample(s.Samples[0], s.Samples[1:]...)
+	}
+	if len(s.SampleContents) > 0 {
+		l.SampleContent(s.SampleContents[0], s.SampleContents[1:]...)
+	}
+	for _, o := range s.Options {
+		var opt *Option[T]
+		if o.Pattern != "" {
+			opt = l.Option(o.Field, o.Pattern)
+		} else {
+			opt = l.Option(o.Field)
+		}
+		if o.Sample != nil {
+			opt.Sample(o.Sample)
+		}
+		if o.Merge != nil {
+			policy, sep, err := o.Merge.merge("option '" + o.Field + "'")
+			if err != nil {
+				return err
+			}
+			opt.Merge(policy, sep...)
+		}
+		if len(o.Bool) > 0 {
+			applySchemaBool(opt.Bool(), o.Bool)
+		}
+	}
+	for _, c := range s.CodeFences {
+		cf := l.CodeFence(c.Field, c.Languages...)
+		if c.Language != "" {
+			cf.Language(c.Language)
+		}
+		if c.Info != "" {
+			cf.Info(c.Info)
+		}
+		if c.SampleCode != "" {
+			cf.SampleCode(c.SampleCode)
+		}
+		if c.SampleInfo != "" {
+			cf.SampleInfo(c.SampleInfo)
+		}
+		for _, name := range sortedKeys(c.Attrs) {
+			cf.Attr(name, c.Attrs[name])
+		}
+		if c.Merge != nil {
+			policy, sep, err := c.Merge.merge("code fence '" + c.Field + "'")
+			if err != nil {
+				return err
+			}
+			cf.Merge(policy, sep...)
+		}
+		for _, name := range c.Validate {
+			validator, ok := schemaValidators[name]
+			if !ok {
+				return fmt.Errorf("invalid schema: unknown validator '%s' of code fence '%s' (json, yaml, go and sql are available)", name, c.Field)
+			}
+			cf.Validate(validator)
+		}
+		if c.IgnoreIndented {
+			cf.IgnoreIndented()
+		}
+		if c.KeepWhitespace {
+			cf.KeepWhitespace()
+		}
+		if c.Dedent {
+			cf.Dedent()
+		}
+		if c.NormalizeNewline {
+			cf.NormalizeNewline()
+		}
+		switch c.Format {
+		case "csv", "tsv":
+			table := cf.CSV()
+			if c.Format == "tsv" {
+				table = cf.TSV()
+			}
+			if c.Table != nil {
+				if err := applySchemaTable(table, *c.Table); err != nil {
+					return err
+				}
+			} else {
+				table.AsMap()
+			}
+		case "json":
+			cf.JSON()
+		case "yaml":
+			cf.YAML()
+		}
+	}
+	if s.Table != nil {
+		if err := applySchemaTable(l.Table(s.Table.Field), *s.Table); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.Children {
+		var child *Layout[T]
+		if c.Repeat {
+			child = l.Children(c.Field, c.Pattern)
+		} else {
+			child = l.Child(c.Field, c.Pattern)
+		}
+		if err := applySchemaLayout(child, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySchemaTable[T any](t *Table[T], s SchemaTable) error {
+	if s.AsMap {
+		t.AsMap()
+	}
+	if s.KeyBy != "" {
+		t.KeyBy(s.KeyBy)
+	}
+	if s.Transpose {
+		t.Transpose()
+	}
+	if s.Extra != "" {
+		t.Extra(s.Extra)
+	}
+	if s.Strict {
+		t.Strict()
+	}
+	if s.Merge != nil {
+		policy, _, err := s.Merge.merge("table '" + s.Field + "'")
+		if err != nil {
+			return err
+		}
+		t.Merge(policy)
+	}
+	for _, f := range s.Fields {
+		if f.Group != nil {
+			g := t.FieldGroup(f.Field, f.Group...)
+			if len(f.Bool) > 0 {
+				applySchemaBool(g.Bool(), f.Bool)
+			}
+			if len(f.Samples) > 0 {
+				g.Samples(f.Samples...)
+			}
+			continue
+		}
+		var sf *StructField[T]
+		if f.Key != "" {
+			sf = t.Field(f.Field, f.Key)
+		} else {
+			sf = t.Field(f.Field)
+		}
+		if f.Required {
+			sf.Required()
+		}
+		if len(f.Samples) > 0 {
+			sf.Samples(f.Samples...)
+		}
+		if f.Split != nil {
+			if f.Split.Regexp != "" {
+				re, err := regexp.Compile(f.Split.Regexp)
+				if err != nil {
+					return fmt.Errorf("invalid schema: split regexp of field '%s': %w", f.Field, err)
+				}
+				sf.SplitRegexp(re)
+			} else if f.Split.Separator != "" {
+				sf.Split(f.Split.Separator)
+			} else {
+				sf.Split(",")
+			}
+			if f.Split.Trim != "" {
+				sf.Trim(f.Split.Trim)
+			}
+			if f.Split.KeepSpace {
+				sf.KeepSpace()
+			}
+		}
+		if len(f.Bool) > 0 {
+			applySchemaBool(sf.Bool(), f.Bool)
+		}
+	}
+	return nil
+}
+
+// mergePolicyNames are names of MergePolicy in schema (indexed by MergePolicy)
+var mergePolicyNames = []string{"error", "append", "concat", "first", "last"}
+
+// merge returns the policy and separator for merge methods
+func (s *SchemaMerge) merge(context string) (MergePolicy, []string, error) {
+	for i, name := range mergePolicyNames {
+		if name != s.Policy {
+			continue
+		}
+		if s.Separator != nil {
+			return MergePolicy(i), []string{*s.Separator}, nil
+		}
+		return MergePolicy(i), nil, nil
+	}
+	return 0, nil, fmt.Errorf("invalid schema: unknown merge policy '%s' of %s (error, append, concat, first and last are available)", s.Policy, context)
+}
+
+// appends returns true if the field is slice by "append" policy
+func (s *SchemaMerge) appends() bool {
+	return s != nil && s.Policy == "append"
+}
+
+var schemaValidators = map[string]func(code, lang, info string) error{
+	"json": ValidateJSON,
+	"yaml": ValidateYAML,
+	"go":   ValidateGo,
+	"sql":  ValidateSQL,
+}
+
+// structBuilder collects fields to create dynamic struct type for untyped result.
+type structBuilder struct {
+	fields []reflect.StructField
+	types  map[string]reflect.Type
+}
+
+func (b *structBuilder) add(name string, t reflect.Type) error {
+	if name == "" {
+		return nil
+	}
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return fmt.Errorf("invalid schema: field name '%s' should be exported Go identifier", name)
+	}
+	if b.types == nil {
+		b.types = make(map[string]reflect.Type)
+	}
+	if et, ok := b.types[name]; ok {
+		if et != t {
+			return fmt.Errorf("invalid schema: field '%s' is defined twice with different types (%s, %s)", name, et, t)
+		}
+		return nil
+	}
+	b.types[name] = t
+	b.fields = append(b.fields, reflect.StructField{
+		Name: name,
+		Type: t,
+		Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s"`, name)),
+	})
+	return nil
+}
+
+func schemaFieldType(name string) (reflect.Type, error) {
+	t, ok := schemaTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid schema: unknown type '%s' (string, int, float and bool are available)", name)
+	}
+	return t, nil
+}
+
+func schemaStructType(s SchemaLayout) (reflect.Type, error) {
+	var b structBuilder
+	if err := b.addLayout(s); err != nil {
+		return nil, err
+	}
+	return reflect.StructOf(b.fields), nil
+}
+
+func (b *structBuilder) addLayout(s SchemaLayout) error {
+	str := reflect.TypeOf("")
+	labelType := str
+	if s.LabelMerge.appends() {
+		labelType = reflect.SliceOf(str)
+	}
+	if err := b.add(s.Label, labelType); err != nil {
+		return err
+	}
+	for _, o := range s.Options {
+		t, err := schemaFieldType(o.Type)
+		if err != nil {
+			return err
+		}
+		if o.Merge.appends() {
+			t = reflect.SliceOf(t)
+		}
+		if err := b.add(o.Field, t); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.CodeFences {
+		t, err := schemaFenceType(c)
+		if err != nil {
+			return err
+		}
+		if err := b.add(c.Field, t); err != nil {
+			return err
+		}
+		for _, name := range []string{c.Language, c.Info} {
+			if err := b.add(name, str); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(c.Attrs) {
+			t := str
+			if name == "highlight" {
+				t = reflect.TypeOf([]int{})
+			}
+			if err := b.add(c.Attrs[name], t); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Table != nil && s.Table.Transpose {
+		rb := b
+		if s.Table.Field != "." {
+			rb = &structBuilder{}
+		}
+		for _, f := range s.Table.Fields {
+			t, err := schemaColumnType(f)
+			if err != nil {
+				return err
+			}
+			if err := rb.add(f.Field, t); err != nil {
+				return err
+			}
+		}
+		if s.Table.Field != "." {
+			if err := b.add(s.Table.Field, reflect.StructOf(rb.fields)); err != nil {
+				return err
+			}
+		}
+	} else if s.Table != nil {
+		tableType, err := schemaTableType(*s.Table)
+		if err != nil {
+			return err
+		}
+		if err := b.add(s.Table.Field, tableType); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.Children {
+		if c.Field == "." {
+			if err := b.addLayout(c); err != nil {
+				return err
+			}
+			continue
+		}
+		t, err := schemaStructType(c)
+		if err != nil {
+			return err
+		}
+		if c.Repeat {
+			t = reflect.SliceOf(t)
+		}
+		if err := b.add(c.Field, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var groupTypes = map[string]reflect.Type{
+	"":    reflect.TypeOf([]string{}),
+	"map": reflect.TypeOf(map[string]bool{}),
+	"int": reflect.TypeOf(int(0)),
+}
+
+// schemaColumnType returns the field type of the table column (slice for split) or the field group
+func schemaColumnType(f SchemaField) (reflect.Type, error) {
+	if f.Group != nil {
+		if f.Key != "" || f.Required || f.Split != nil {
+			return nil, fmt.Errorf("invalid schema: field group '%s' can't have key, required and split", f.Field)
+		}
+		t, ok := groupTypes[f.Type]
+		if !ok {
+			return nil, fmt.Errorf("invalid schema: unknown type '%s' of field group '%s' (map and int are available)", f.Type, f.Field)
+		}
+		return t, nil
+	}
+	t, err := schemaFieldType(f.Type)
+	if err != nil {
+		return nil, err
+	}
+	if f.Split != nil {
+		if f.Split.Separator != "" && f.Split.Regexp != "" {
+			return nil, fmt.Errorf("invalid schema: split of field '%s' can't have both separator and regexp", f.Field)
+		}
+		t = reflect.SliceOf(t)
+	}
+	return t, nil
+}
+
+// schemaGroupTypeName returns Type of SchemaField for field group of type t
+func schemaGroupTypeName(t reflect.Type) string {
+	switch {
+	case t == nil:
+	case t.Kind() == reflect.Map:
+		return "map"
+	case isIntKind(t.Kind()):
+		return "int"
+	}
+	return ""
+}
+
+// schemaTableType returns the field type of the table (not transposed)
+func schemaTableType(s SchemaTable) (reflect.Type, error) {
+	var rowType reflect.Type
+	if s.AsMap {
+		rowType = reflect.TypeOf(map[string]string{})
+	} else {
+		var rb structBuilder
+		for _, f := range s.Fields {
+			t, err := schemaColumnType(f)
+			if err != nil {
+				return nil, err
+			}
+			if err := rb.add(f.Field, t); err != nil {
+				return nil, err
+			}
+		}
+		if err := rb.add(s.Extra, reflect.TypeOf(map[string]string{})); err != nil {
+			return nil, err
+		}
+		rowType = reflect.StructOf(rb.fields)
+	}
+	if s.KeyBy != "" {
+		return reflect.MapOf(reflect.TypeOf(""), rowType), nil
+	}
+	return reflect.SliceOf(rowType), nil
+}
+
+var fenceTypes = map[string]reflect.Type{
+	"":         reflect.TypeOf(""),
+	"list":     reflect.TypeOf([]string{}),
+	"map":      reflect.TypeOf(map[string]string{}),
+	"snippets": reflect.TypeOf([]Snippet{}),
+}
+
+// schemaFenceType returns the field type of the code fence
+func schemaFenceType(c SchemaCodeFence) (reflect.Type, error) {
+	if c.Format != "" && c.Type != "" {
+		return nil, fmt.Errorf("invalid schema: code fence '%s' can't have both type and format", c.Field)
+	}
+	switch c.Format {
+	case "":
+	case "csv", "tsv":
+		if c.Table == nil {
+			return reflect.TypeOf([]map[string]string{}), nil
+		}
+		return schemaTableType(*c.Table)
+	case "json", "yaml":
+		return reflect.TypeOf((*any)(nil)).Elem(), nil
+	default:
+		return nil, fmt.Errorf("invalid schema: unknown format '%s' of code fence '%s' (csv, tsv, json and yaml are available)", c.Format, c.Field)
+	}
+	if c.Type == "" && c.Merge.appends() {
+		return reflect.TypeOf([]string{}), nil
+	}
+	t, ok := fenceTypes[c.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid schema: unknown type '%s' of code fence '%s' (list, map and snippets are available)", c.Type, c.Field)
+	}
+	return t, nil
+}
+
+// schemaFenceTypeName returns Type of SchemaCodeFence for field type t
+func schemaFenceTypeName(t reflect.Type) string {
+	switch {
+	case t == nil:
+	case t.Kind() == reflect.Slice && t.Elem() == snippetType:
+		return "snippets"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return "list"
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String:
+		return "map"
+	}
+	return ""
+}
+
+// toUntyped converts dynamic struct into map[string]any
+func toUntyped(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toUntyped(v.Elem())
+	case reflect.Struct:
+		result := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			result[v.Type().Field(i).Name] = toUntyped(v.Field(i))
+		}
+		return result
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			if v.IsNil() {
+				return []any{}
+			}
+			result := make([]any, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				result[i] = v.Index(i).Interface()
+			}
+			return result
+		}
+		result := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result[i] = toUntyped(v.Index(i))
+		}
+		return result
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return v.Interface()
+		}
+		result := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = toUntyped(iter.Value())
+		}
+		return result
+	}
+	return v.Interface()
+}
+
+// Schema exports definition of this jig as [Schema]
+//
+// Converters specified by [StructField.As] are not exported. It returns error if the jig
+// has definitions that schema can't express (custom validators and language guesser).
+func (j *DocJig[T]) Schema() (*Schema, error) {
+	root, err := j.root.schema(j.rootType())
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{
+		Root: root,
+		Bool: schemaBool(j.bools, j.DefaultLang),
+	}
+	if j.guessLanguage != nil {
+		if !sameFunc(j.guessLanguage, GuessLanguage) {
+			return nil, fmt.Errorf("schema can't express custom language guesser")
+		}
+		s.GuessLanguage = true
+	}
+	if j.DefaultLang != "en" {
+		s.DefaultLang = j.DefaultLang
+	}
+	keys := make([]string, 0, len(j.aliases))
+	for k := range j.aliases {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		as := j.aliases[k]
+		sa := SchemaAlias{
+			Label: as[0].label,
+		}
+		for _, a := range as[1:] {
+			if a.lang == j.DefaultLang {
+				sa.Aliases = append(sa.Aliases, a.label)
+			} else {
+				if sa.Langs == nil {
+					sa.Langs = make(map[string][]string)
+				}
+				sa.Langs[a.lang] = append(sa.Langs[a.lang], a.label)
+			}
+		}
+		s.Aliases = append(s.Aliases, sa)
+	}
+	return s, nil
+}
+
+func (l *Layout[T]) schema(t reflect.Type) (SchemaLayout, error) {
+	s := SchemaLayout{
+		Field:          l.instanceFieldName,
+		Pattern:        l.labelPattern,
+		Repeat:         l.repeat,
+		Label:          l.labelFieldName,
+		LabelMerge:     schemaMerge(l.labelMerge),
+		Samples:        l.samples,
+		SampleContents: l.sampleContents,
+	}
+	for _, o := range l.options {
+		ft := resolveField(t, o.fieldName).typ(t)
+		if o.merge.policy == MergeAppend && ft != nil && ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		so := SchemaOption{
+			Field:  o.fieldName,
+			Type:   schemaTypeName(ft),
+			Sample: o.sample,
+			Merge:  schemaMerge(o.merge),
+			Bool:   schemaBool(o.bools, l.j.DefaultLang),
+		}
+		if o.pattern != o.fieldName {
+			so.Pattern = o.pattern
+		}
+		s.Options = append(s.Options, so)
+	}
+	for _, cf := range l.codeFences {
+		sc, err := cf.schema(t)
+		if err != nil {
+			return s, err
+		}
+		s.CodeFences = append(s.CodeFences, sc)
+	}
+	if l.table != nil {
+		st, err := l.table.schema(t)
+		if err != nil {
+			return s, err
+		}
+		s.Table = st
+	}
+	for _, c := range l.children {
+		var ct reflect.Type
+		if c.instanceFieldName == "." {
+			ct = t
+		} else if ft := resolveField(t, c.instanceFieldName).typ(t); ft != nil {
+			ct = indirectType(ft)
+			if c.repeat && ct.Kind() == reflect.Slice {
+				ct = indirectType(ct.Elem())
+			}
+		}
+		sc, err := c.schema(ct)
+		if err != nil {
+			return s, err
+		}
+		s.Children = append(s.Children, sc)
+	}
+	return s, nil
+}
+
+func (cf *CodeFence[T]) schema(t reflect.Type) (SchemaCodeFence, error) {
+	s := SchemaCodeFence{
+		Field:            cf.fieldName,
+		Languages:        cf.targetLanguages,
+		Language:         cf.languageFieldName,
+		Info:             cf.infoFieldName,
+		SampleCode:       cf.sampleCode,
+		SampleInfo:       cf.sampleInfo,
+		Format:           cf.data.String(),
+		Merge:            schemaMerge(cf.merge),
+		IgnoreIndented:   cf.ignoreIndented,
+		KeepWhitespace:   cf.keepWhitespace,
+		Dedent:           cf.dedent,
+		NormalizeNewline: cf.normalizeNewline,
+	}
+	for _, v := range cf.validators {
+		name := validatorName(v)
+		if name == "" {
+			return s, fmt.Errorf("schema can't express custom validator of code fence '%s'", cf.fieldName)
+		}
+		s.Validate = append(s.Validate, name)
+	}
+	for _, a := range cf.attrs {
+		if s.Attrs == nil {
+			s.Attrs = make(map[string]string)
+		}
+		s.Attrs[a.name] = a.fieldName
+	}
+	if cf.data == dataNone {
+		s.Type = schemaFenceTypeName(resolveField(t, cf.fieldName).typ(t))
+	}
+	if cf.table != nil {
+		st, err := cf.table.schema(t)
+		if err != nil {
+			return s, err
+		}
+		s.Table = st
+	}
+	return s, nil
+}
+
+func (t *Table[T]) schema(target reflect.Type) (*SchemaTable, error) {
+	s := &SchemaTable{
+		Field:     t.fieldName,
+		AsMap:     t.asMap,
+		KeyBy:     t.keyBy,
+		Transpose: t.transpose,
+		Extra:     t.extra,
+		Strict:    t.strict,
+	}
+	if t.merge != nil {
+		s.Merge = &SchemaMerge{Policy: mergePolicyNames[t.merge.policy]}
+	}
+	var rowType reflect.Type
+	if t.transpose {
+		if t.fieldName == "." {
+			rowType = target
+		} else if ft := resolveField(target, t.fieldName).typ(target); ft != nil {
+			rowType = indirectType(ft)
+		}
+	} else if ft := resolveField(target, t.fieldName).typ(target); ft != nil && (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map) {
+		rowType = indirectType(ft.Elem())
+	}
+	for _, item := range t.items {
+		if g := item.group; g != nil {
+			s.Fields = append(s.Fields, SchemaField{
+				Field:   g.fieldName,
+				Group:   g.columns,
+				Type:    schemaGroupTypeName(resolveField(rowType, g.fieldName).typ(rowType)),
+				Bool:    schemaBool(g.bools, t.j.DefaultLang),
+				Samples: g.samples,
+			})
+			continue
+		}
+		f := item.field
+		ft := resolveField(rowType, f.fieldName).typ(rowType)
+		if f.split != nil && ft != nil && ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		sf := SchemaField{
+			Field:    f.fieldName,
+			Required: f.required,
+			Type:     schemaTypeName(ft),
+			Split:    f.split.schema(),
+			Bool:     schemaBool(f.bools, t.j.DefaultLang),
+			Samples:  f.samples,
+		}
+		if f.origKey != f.fieldName {
+			sf.Key = f.origKey
+		}
+		s.Fields = append(s.Fields, sf)
+	}
+	return s, nil
+}
+
+// validatorName returns the name of built-in validator ("" for custom validators)
+func validatorName(v func(code, lang, info string) error) string {
+	for name, builtin := range schemaValidators {
+		if sameFunc(v, builtin) {
+			return name
+		}
+	}
+	return ""
+}
+
+func sameFunc[F any](a, b F) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+func (r *splitRule) schema() *SchemaSplit {
+	if r == nil {
+		return nil
+	}
+	s := &SchemaSplit{
+		Trim:      r.cutset,
+		KeepSpace: r.keepSpace,
+	}
+	if r.re != nil {
+		s.Regexp = r.re.String()
+	} else if r.sep != "," {
+		s.Separator = r.sep
+	}
+	return s
+}
+
+// schemaMerge returns nil for the default policy
+func schemaMerge(m merge) *SchemaMerge {
+	if m.policy == MergeError {
+		return nil
+	}
+	s := &SchemaMerge{Policy: mergePolicyNames[m.policy]}
+	if m.separator != "\n" {
+		sep := m.separator
+		s.Separator = &sep
+	}
+	return s
+}
+
+// schemaBool groups words by language. Words of defaultLang have empty Lang.
+func schemaBool(v *boolVocabulary, defaultLang string) []SchemaBool {
+	if v == nil {
+		return nil
+	}
+	var result []SchemaBool
+	index := make(map[string]int)
+	for _, w := range v.words {
+		i, ok := index[w.lang]
+		if !ok {
+			i = len(result)
+			index[w.lang] = i
+			lang := w.lang
+			if lang == defaultLang {
+				lang = ""
+			}
+			result = append(result, SchemaBool{Lang: lang})
+		}
+		if w.value {
+			result[i].True = append(result[i].True, w.word)
+		} else {
+			result[i].False = append(result[i].False, w.word)
+		}
+	}
+	return result
+}
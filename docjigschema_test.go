@@ -0,0 +1,899 @@
+package mdd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDocJigFromSchema(t *testing.T) {
+	schemaSrc := TrimIndent(t, `
+	aliases:
+	  - label: CRUD Matrix
+	    langs:
+	      ja: [CRUDマトリックス]
+	root:
+	  label: Name
+	  codeFences:
+	    - field: SQL
+	      languages: [sql]
+	  children:
+	    - field: .
+	      pattern: CRUD Matrix
+	      table:
+	        field: CRUDMatrix
+	        fields:
+	          - field: Table
+	            required: true
+	          - field: R
+	            type: bool
+	    - field: Queries
+	      pattern: Query
+	      repeat: true
+	      label: Name
+	      options:
+	        - field: Limit
+	          type: int
+	`)
+
+	schema, err := LoadSchema(strings.NewReader(schemaSrc))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Query User
+
+	~~~sql
+	select * from users;
+	~~~
+
+	## CRUDマトリックス
+
+	| Table | R |
+	|-------|---|
+	| users | X |
+
+	## Query: first (Limit=10)
+
+	## Query: second
+	`))
+	assert.NoError(t, err)
+
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Name": "Query User",
+		"SQL": "select * from users;",
+		"CRUDMatrix": [{"Table": "users", "R": true}],
+		"Queries": [
+			{"Name": "first", "Limit": 10},
+			{"Name": "second", "Limit": 0}
+		]
+	}`, string(j))
+}
+
+func TestNewDocJigFromSchema_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "unknown type",
+			src: TrimIndent(t, `
+			root:
+			  options:
+			    - field: Opt
+			      type: time
+			`),
+			wantErr: "invalid schema: unknown type 'time' (string, int, float and bool are available)",
+		},
+		{
+			name: "invalid field name",
+			src: TrimIndent(t, `
+			root:
+			  label: name
+			`),
+			wantErr: "invalid schema: field name 'name' should be exported Go identifier",
+		},
+		{
+			name: "type conflict",
+			src: TrimIndent(t, `
+			root:
+			  label: Name
+			  options:
+			    - field: Name
+			      type: int
+			`),
+			wantErr: "invalid schema: field 'Name' is defined twice with different types (string, int)",
+		},
+		{
+			name: "unknown fence type",
+			src: TrimIndent(t, `
+			root:
+			  codeFences:
+			    - field: Code
+			      type: set
+			`),
+			wantErr: "invalid schema: unknown type 'set' of code fence 'Code' (list, map and snippets are available)",
+		},
+		{
+			name: "unknown format",
+			src: TrimIndent(t, `
+			root:
+			  codeFences:
+			    - field: Data
+			      format: xml
+			`),
+			wantErr: "invalid schema: unknown format 'xml' of code fence 'Data' (csv, tsv, json and yaml are available)",
+		},
+		{
+			name: "type and format",
+			src: TrimIndent(t, `
+			root:
+			  codeFences:
+			    - field: Data
+			      type: list
+			      format: json
+			`),
+			wantErr: "invalid schema: code fence 'Data' can't have both type and format",
+		},
+		{
+			name: "unknown merge policy",
+			src: TrimIndent(t, `
+			root:
+			  options:
+			    - field: Opt
+			      merge:
+			        policy: join
+			`),
+			wantErr: "invalid schema: unknown merge policy 'join' of option 'Opt' (error, append, concat, first and last are available)",
+		},
+		{
+			name: "unknown group type",
+			src: TrimIndent(t, `
+			root:
+			  table:
+			    field: Rows
+			    fields:
+			      - field: Ops
+			        group: [C, R]
+			        type: bool
+			`),
+			wantErr: "invalid schema: unknown type 'bool' of field group 'Ops' (map and int are available)",
+		},
+		{
+			name: "invalid split regexp",
+			src: TrimIndent(t, `
+			root:
+			  table:
+			    field: Rows
+			    fields:
+			      - field: Tags
+			        split:
+			          regexp: "("
+			`),
+			wantErr: "invalid schema: split regexp of field 'Tags': error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "unknown validator",
+			src: TrimIndent(t, `
+			root:
+			  codeFences:
+			    - field: Code
+			      validate: [xml]
+			`),
+			wantErr: "invalid schema: unknown validator 'xml' of code fence 'Code' (json, yaml, go and sql are available)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, err := LoadSchema(strings.NewReader(tc.src))
+			assert.NoError(t, err)
+			_, err = NewDocJigFromSchema(schema)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestDocJig_Schema(t *testing.T) {
+	type CRUD struct {
+		Table       string
+		R           bool
+		Description string
+	}
+
+	type Doc struct {
+		Name string
+		SQL  string
+		Lang string
+		CRUD []CRUD
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Alias("Description", "Desc").Lang("ja", "説明")
+	root := jig.Root()
+	root.Label("Name")
+	root.CodeFence("SQL", "sql").Language("Lang")
+	crud := root.Child(".", "CRUD Matrix").Table("CRUD")
+	crud.Field("Table").Required()
+	crud.Field("R")
+	crud.Field("Description", "Desc")
+
+	var buffer bytes.Buffer
+	err := schemaOf(t, jig).WriteYAML(&buffer)
+	assert.NoError(t, err)
+	want := TrimIndent(t, `
+	aliases:
+	  - label: Description
+	    aliases:
+	      - Desc
+	    langs:
+	      ja:
+	        - 説明
+	root:
+	  label: Name
+	  codeFences:
+	    - field: SQL
+	      languages:
+	        - sql
+	      language: Lang
+	  children:
+	    - field: .
+	      pattern: CRUD Matrix
+	      table:
+	        field: CRUD
+	        fields:
+	          - field: Table
+	            required: true
+	          - field: R
+	            type: bool
+	          - field: Description
+	            key: Desc
+	`)
+	assert.Equal(t, want, strings.TrimRight(buffer.String(), "\n"))
+
+	// round trip
+	schema, err := LoadSchema(&buffer)
+	assert.NoError(t, err)
+	untyped, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schemaOf(t, jig), schemaOf(t, untyped))
+}
+
+func TestNewDocJigFromSchema_KeyBy(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  table:
+	    field: CRUD
+	    keyBy: Table
+	    fields:
+	      - field: Table
+	      - field: R
+	        type: bool
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Query User
+
+	| Table | R |
+	|-------|---|
+	| users | X |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"users": map[string]any{"Table": "users", "R": true},
+	}, (*got)["CRUD"])
+	assert.Equal(t, schema, schemaOf(t, jig))
+}
+
+func TestNewDocJigFromSchema_Transpose(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  label: Name
+	  table:
+	    field: .
+	    transpose: true
+	    fields:
+	      - field: MaxRows
+	        key: Max Rows
+	        required: true
+	        type: int
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Settings
+
+	| Property | Value |
+	|----------|-------|
+	| Max Rows | 100   |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &map[string]any{"Name": "Settings", "MaxRows": 100}, got)
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample"}))
+	assert.Contains(t, b.String(), "\tMaxRows int\n")
+	assert.Contains(t, b.String(), "table := root.Table(\".\")\n\ttable.Transpose()\n")
+}
+
+func TestNewDocJigFromSchema_FenceAttrs(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  codeFences:
+	    - field: Code
+	      languages: [go]
+	      attrs:
+	        title: FileName
+	        highlight: Lines
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	~~~go title="main.go" {2}
+	package main
+	~~~
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, "main.go", (*got)["FileName"])
+	assert.Equal(t, []any{2}, (*got)["Lines"])
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "`mdd:\"fence=go,attr=highlight:Lines|title:FileName\"`")
+	assert.Contains(t, b.String(), "\tLines    []int\n")
+}
+
+func TestNewDocJigFromSchema_DataFence(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  codeFences:
+	    - field: Users
+	      languages: [csv]
+	      format: csv
+	      table:
+	        field: Users
+	        fields:
+	          - field: Name
+	            required: true
+	          - field: Age
+	            type: int
+	    - field: Config
+	      languages: [json]
+	      format: json
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	~~~csv
+	Name,Age
+	alice,20
+	~~~
+
+	~~~json
+	{"debug": true}
+	~~~
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Users": [{"Name": "alice", "Age": 20}],
+		"Config": {"debug": true}
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "\tUsers  []DocUsers `mdd:\"fence=csv,format=csv\"`\n")
+	assert.Contains(t, b.String(), "\tConfig any        `mdd:\"fence=json,format=json\"`\n")
+	assert.Contains(t, b.String(), "\tName string `mdd:\"required\"`\n")
+
+	b.Reset()
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample"}))
+	assert.Contains(t, b.String(), "table := root.CodeFence(\"Users\", \"csv\").CSV()\n\t\ttable.Field(\"Name\").Required()\n")
+	assert.Contains(t, b.String(), "root.CodeFence(\"Config\", \"json\").JSON()\n")
+}
+
+func TestNewDocJigFromSchema_FenceKinds(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  codeFences:
+	    - field: SQL
+	      languages: [sql]
+	      type: list
+	    - field: Files
+	      languages: [go]
+	      type: map
+	    - field: Snippets
+	      languages: [sh]
+	      type: snippets
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	~~~sql
+	select 1;
+	~~~
+
+	~~~sql
+	select 2;
+	~~~
+
+	~~~go :main.go
+	package main
+	~~~
+
+	~~~sh
+	ls
+	~~~
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"SQL": ["select 1;", "select 2;"],
+		"Files": {"main.go": "package main"},
+		"Snippets": [{"Code": "ls", "Lang": "sh", "Info": "", "Attrs": null}]
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "\tSQL      []string          `mdd:\"fence=sql\"`\n")
+	assert.Contains(t, b.String(), "\tFiles    map[string]string `mdd:\"fence=go\"`\n")
+	assert.Contains(t, b.String(), "\tSnippets []mdd.Snippet     `mdd:\"fence=sh\"`\n")
+}
+
+func TestNewDocJigFromSchema_Merge(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  label: Name
+	  children:
+	    - field: .
+	      pattern: Part
+	      repeat: true
+	      label: Parts
+	      labelMerge:
+	        policy: append
+	      options:
+	        - field: Tags
+	          merge:
+	            policy: append
+	      codeFences:
+	        - field: SQL
+	          languages: [sql]
+	          merge:
+	            policy: concat
+	            separator: "\n\n"
+	    - field: .
+	      pattern: Tasks
+	      table:
+	        field: Tasks
+	        merge:
+	          policy: last
+	        fields:
+	          - field: Task
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	## Part: one (Tags=a)
+
+	~~~sql
+	select 1;
+	~~~
+
+	## Part: two (Tags=b)
+
+	~~~sql
+	select 2;
+	~~~
+
+	## Tasks
+
+	| Task  |
+	|-------|
+	| write |
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Name": "Sample",
+		"Parts": ["one", "two"],
+		"Tags": ["a", "b"],
+		"SQL": "select 1;\n\nselect 2;",
+		"Tasks": [{"Task": "write"}]
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJig[Doc]()")
+	assert.Contains(t, b.String(), "\tParts []string\n")
+	assert.Contains(t, b.String(), "\tTags  []string\n")
+	assert.Contains(t, b.String(), "level2.MergeLabel(mdd.MergeAppend)\n")
+	assert.Contains(t, b.String(), "level2.Option(\"Tags\").Merge(mdd.MergeAppend)\n")
+	assert.Contains(t, b.String(), "level2.CodeFence(\"SQL\", \"sql\").Merge(mdd.MergeConcat, \"\\n\\n\")\n")
+	assert.Contains(t, b.String(), "table.Merge(mdd.MergeLast)\n")
+}
+
+func TestNewDocJigFromSchema_Bool(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	bool:
+	  - true: [done]
+	    false: [todo]
+	root:
+	  label: Name
+	  options:
+	    - field: Public
+	      type: bool
+	      bool:
+	        - true: [public]
+	          false: [private]
+	  table:
+	    field: Tasks
+	    fields:
+	      - field: Task
+	      - field: Done
+	        type: bool
+	      - field: Check
+	        type: bool
+	        bool:
+	          - lang: ja
+	            true: [済]
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample (Public=private)
+
+	| Task  | Done | Check |
+	|-------|------|-------|
+	| write | done | 済    |
+	| read  | todo |       |
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Name": "Sample",
+		"Public": false,
+		"Tasks": [{"Task": "write", "Done": true, "Check": true}, {"Task": "read", "Done": false, "Check": false}]
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJig[Doc]()")
+	assert.Contains(t, b.String(), "DocJig.Bool().True(\"done\").False(\"todo\")\n")
+	assert.Contains(t, b.String(), "root.Option(\"Public\").Bool().True(\"public\").False(\"private\")\n")
+	assert.Contains(t, b.String(), "table.Field(\"Check\").Bool().Lang(\"ja\").True(\"済\")\n")
+}
+
+func TestNewDocJigFromSchema_FieldGroup(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  table:
+	    field: Tasks
+	    fields:
+	      - field: Task
+	      - field: Ops
+	        group: [C, R]
+	        type: int
+	      - field: Flags
+	        group: [A, B]
+	        type: map
+	        bool:
+	          - true: [o]
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	| Task  | C | R | A | B |
+	|-------|---|---|---|---|
+	| write | x |   |   | o |
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Tasks": [{"Task": "write", "Ops": 1, "Flags": {"A": false, "B": true}}]
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "\tOps   int\n")
+	assert.Contains(t, b.String(), "\tFlags map[string]bool\n")
+	assert.Contains(t, b.String(), "table.FieldGroup(\"Ops\", \"C\", \"R\")\n")
+	assert.Contains(t, b.String(), "table.FieldGroup(\"Flags\", \"A\", \"B\").Bool().True(\"o\")\n")
+}
+
+func TestNewDocJigFromSchema_TagsForGroup(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  table:
+	    field: Tasks
+	    fields:
+	      - field: Task
+	      - field: Ops
+	        group: [C, R]
+	        samples: [[C]]
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var tmpl bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&tmpl))
+	assert.Contains(t, tmpl.String(), "| ...  | X   |     |\n")
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "\tOps  []string `mdd:\"group=C|R\"`\n")
+	assert.Contains(t, b.String(), "table.FieldGroup(\"Ops\", \"C\", \"R\").Samples([]interface{}{\"C\"})\n")
+}
+
+func TestNewDocJigFromSchema_Split(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  table:
+	    field: Tasks
+	    fields:
+	      - field: Task
+	      - field: Owners
+	        split:
+	          separator: /
+	      - field: Points
+	        type: int
+	        split:
+	          regexp: ";|,"
+	          trim: "[]"
+	      - field: Tags
+	        split: {}
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	| Task  | Owners      | Points     | Tags |
+	|-------|-------------|------------|------|
+	| write | alice / bob | [1]; 2, 3  | a, b |
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Tasks": [{"Task": "write", "Owners": ["alice", "bob"], "Points": [1, 2, 3], "Tags": ["a", "b"]}]
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "\t\"regexp\"\n")
+	assert.Contains(t, b.String(), "\tOwners []string\n")
+	assert.Contains(t, b.String(), "\tPoints []int\n")
+	assert.Contains(t, b.String(), "table.Field(\"Owners\").Split(\"/\")\n")
+	assert.Contains(t, b.String(), "table.Field(\"Points\").SplitRegexp(regexp.MustCompile(\";|,\")).Trim(\"[]\")\n")
+	assert.Contains(t, b.String(), "table.Field(\"Tags\").Split(\",\")\n")
+}
+
+func TestNewDocJigFromSchema_Extra(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  table:
+	    field: Tasks
+	    extra: Others
+	    fields:
+	      - field: Task
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	| Task  | Memo |
+	|-------|------|
+	| write | m    |
+	`))
+	assert.NoError(t, err)
+	j, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Tasks": [{"Task": "write", "Others": {"Memo": "m"}}]
+	}`, string(j))
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJigFromTags[Doc]()")
+	assert.Contains(t, b.String(), "\tOthers map[string]string `mdd:\"extra\"`\n")
+}
+
+func TestNewDocJigFromSchema_Strict(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  table:
+	    field: Tasks
+	    strict: true
+	    fields:
+	      - field: Task
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	_, err = jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	| Task  | Memo |
+	|-------|------|
+	| write | m    |
+	`))
+	assert.ErrorContains(t, err, "Memo")
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJig[Doc]()")
+	assert.Contains(t, b.String(), "table.Strict()\n")
+}
+
+func TestNewDocJigFromSchema_GuessLanguage(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	guessLanguage: true
+	root:
+	  codeFences:
+	    - field: Code
+	      languages: [go]
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	~~~
+	package main
+
+	func main() {
+	}
+	~~~
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n}", (*got)["Code"])
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJig[Doc]()")
+	assert.Contains(t, b.String(), "DocJig.GuessLanguage()\n")
+}
+
+func TestNewDocJigFromSchema_Whitespace(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  codeFences:
+	    - field: Code
+	      languages: [go]
+	      ignoreIndented: true
+	      dedent: true
+	      normalizeNewline: true
+	    - field: Raw
+	      languages: [text]
+	      keepWhitespace: true
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	got, err := jig.ParseString("# Sample\n\n~~~go\r\n    a()\r\n      b()\r\n~~~\n\n~~~text\n  raw  \n~~~\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "a()\n  b()", (*got)["Code"])
+	assert.Equal(t, "  raw  \n", (*got)["Raw"])
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJig[Doc]()")
+	assert.Contains(t, b.String(), "root.CodeFence(\"Code\", \"go\").IgnoreIndented().Dedent().NormalizeNewline()\n")
+	assert.Contains(t, b.String(), "root.CodeFence(\"Raw\", \"text\").KeepWhitespace()\n")
+}
+
+func TestNewDocJigFromSchema_Validate(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(TrimIndent(t, `
+	root:
+	  codeFences:
+	    - field: SQL
+	      languages: [sql]
+	      validate: [sql]
+	`)))
+	assert.NoError(t, err)
+	jig, err := NewDocJigFromSchema(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, schema, schemaOf(t, jig))
+
+	_, err = jig.ParseString(TrimIndent(t, `
+	# Sample
+
+	~~~sql
+	select 'a;
+	~~~
+	`))
+	assert.ErrorContains(t, err, "invalid sql code fence")
+
+	var b bytes.Buffer
+	assert.NoError(t, schema.GenerateGo(&b, GenerateGoOption{Package: "sample", Tags: true}))
+	assert.Contains(t, b.String(), "mdd.NewDocJig[Doc]()")
+	assert.Contains(t, b.String(), "root.CodeFence(\"SQL\", \"sql\").Validate(mdd.ValidateSQL)\n")
+}
+
+func TestDocJig_Schema_Error(t *testing.T) {
+	type Doc struct {
+		Code string
+	}
+
+	jig := NewDocJig[Doc]()
+	jig.Root().CodeFence("Code").Validate(func(code, lang, info string) error {
+		return nil
+	})
+	_, err := jig.Schema()
+	assert.EqualError(t, err, "schema can't express custom validator of code fence 'Code'")
+
+	jig = NewDocJig[Doc]()
+	jig.GuessLanguage(func(code string) string {
+		return "go"
+	})
+	_, err = jig.Schema()
+	assert.EqualError(t, err, "schema can't express custom language guesser")
+}
+
+func schemaOf[T any](t *testing.T, jig *DocJig[T]) *Schema {
+	t.Helper()
+	s, err := jig.Schema()
+	assert.NoError(t, err)
+	return s
+}
@@ -6,7 +6,6 @@ import (
 	"reflect"
 	"strings"
 
-	"github.com/future-architect/tagscanner/runtimescan"
 	"github.com/shibukawa/formatdata-go"
 )
 
@@ -14,77 +13,289 @@ type Table[T any] struct {
 	j         *DocJig[T]
 	fieldName string
 	fields    []*StructField[T]
+	items     []tableItem[T] // fields and field groups in definition order
 	asMap     bool
+	extra     string
+	strict    bool
+	keyBy     string
+	transpose bool
+	merge     *merge // nil means MergeAppend
+
+	// compiled plan
+	ref      fieldRef
+	rowType  reflect.Type
+	rowPtr   bool // map[string]*Row
+	columns  map[string]int
+	extraRef fieldRef
+}
+
+// tableItem is a column definition. One of field or group is set.
+type tableItem[T any] struct {
+	field *StructField[T]
+	group *FieldGroup[T]
 }
 
+// Field binds the column of key (field name if empty) to the field
+//
+// Calling it again with the same field and key returns the existing definition to refine it.
+// Other keys add another column to the same field.
 func (t *Table[T]) Field(fieldName string, key ...string) *StructField[T] {
-	var k string
-	var origK string
+	origK := lastName(fieldName)
 	if len(key) > 0 {
-		k = strings.ToLower(key[0])
 		origK = key[0]
-	} else {
-		k = strings.ToLower(fieldName)
-		origK = fieldName
 	}
+	for _, f := range t.fields {
+		if f.fieldName == fieldName && f.origKey == origK {
+			return f // refine existing definition
+		}
+	}
+	t.j.invalidate()
 	f := &StructField[T]{
+		j:         t.j,
 		fieldName: fieldName,
-		key:       k,
+		key:       strings.ToLower(origK),
 		origKey:   origK,
 	}
 	t.fields = append(t.fields, f)
+	t.items = append(t.items, tableItem[T]{field: f})
 	return f
 }
 
-func (t *Table[T]) AsMap() {
+func (t *Table[T]) AsMap() *Table[T] {
 	t.asMap = true
+	return t
 }
 
-func (t Table[T]) assignCells(target reflect.Value, cells []map[string]string, key2column map[string]int, label string, doc *T) error {
-	if t.asMap {
-		return t.assignCellsAsMap(target, cells, key2column, label, doc)
+// Extra collects undeclared columns of each row into the map[string]string field
+//
+// Keys are header texts in the original spelling.
+func (t *Table[T]) Extra(fieldName string) *Table[T] {
+	t.j.invalidate()
+	t.extra = fieldName
+	return t
+}
+
+// KeyBy stores rows into map keyed by the column instead of slice
+//
+// The field type should be map[string]Row, map[string]*Row or map[string]map[string]string (with [Table.AsMap]).
+// Duplicated or empty keys are reported as error.
+func (t *Table[T]) KeyBy(column string) *Table[T] {
+	t.j.invalidate()
+	t.keyBy = column
+	return t
+}
+
+// keyColumn returns header index of KeyBy column
+func (t Table[T]) keyColumn(headers []string) int {
+	key := strings.ToLower(t.keyBy)
+	for c, h := range headers {
+		lh := strings.ToLower(h)
+		if lh == key {
+			return c
+		}
+		for _, a := range t.j.aliases[key] {
+			if a.lowLabel == lh {
+				return c
+			}
+		}
+	}
+	return -1
+}
+
+// Merge specifies the behavior when several tables are stored into the same field
+//
+// Default is [MergeAppend] (rows of tables are appended). [MergeConcat] is same as [MergeAppend].
+func (t *Table[T]) Merge(policy MergePolicy) *Table[T] {
+	t.merge = &merge{policy: policy}
+	return t
+}
+
+// destination returns the field to store rows and checks its type.
+//
+// If skip is true, rows should not be stored by merge policy.
+func (t Table[T]) destination(target reflect.Value, headers []string, label string) (dest reflect.Value, keyColumn int, skip bool, err error) {
+	dest = t.ref.field(target)
+	if t.keyBy == "" {
+		if dest.Kind() != reflect.Slice {
+			return dest, -1, false, fmt.Errorf("field '%s' of %s is not slice type (inside '%s' section)", t.fieldName, target.Type(), label)
+		}
 	} else {
-		return t.assignCellsAsStruct(target, cells, key2column, label, doc)
+		if dest.Kind() != reflect.Map || dest.Type().Key().Kind() != reflect.String {
+			return dest, -1, false, fmt.Errorf("field '%s' of %s is not map type (inside '%s' section)", t.fieldName, target.Type(), label)
+		}
+		keyColumn = t.keyColumn(headers)
+		if keyColumn == -1 {
+			return dest, -1, false, fmt.Errorf("key column(%s) is missing in table '%s' (inside '%s' section)", t.keyBy, t.fieldName, label)
+		}
+	}
+	if t.merge != nil && dest.Len() > 0 {
+		switch t.merge.policy {
+		case MergeError:
+			return dest, -1, false, fmt.Errorf("field '%s' for table is already filled (inside '%s' section)", t.fieldName, label)
+		case MergeFirst:
+			return dest, -1, true, nil
+		case MergeLast:
+			dest.Set(reflect.Zero(dest.Type()))
+		}
+	}
+	if t.keyBy == "" {
+		return dest, -1, false, nil
 	}
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(dest.Type()))
+	}
+	return dest, keyColumn, false, nil
 }
 
-func (t Table[T]) assignCellsAsStruct(target reflect.Value, cells []map[string]string, key2column map[string]int, label string, doc *T) error {
-	slice := target.FieldByName(t.fieldName)
-	if slice.Kind() != reflect.Slice {
-		return fmt.Errorf("field '%s' of %s is not slice type (inside '%s' section)", t.fieldName, target.Type(), label)
+// storeByKey stores row into map with duplication check. firstRows keeps row numbers of this table.
+func (t Table[T]) storeByKey(dest reflect.Value, key string, row reflect.Value, rowNum int, firstRows map[string]int, label string) error {
+	if key == "" {
+		return fmt.Errorf("key column(%s) is empty at row %d in table '%s' (inside '%s' section)", t.keyBy, rowNum, t.fieldName, label)
+	}
+	if first, ok := firstRows[key]; ok {
+		return fmt.Errorf("duplicate key '%s' at row %d (first at row %d) in table '%s' (inside '%s' section)", key, rowNum, first, t.fieldName, label)
 	}
-	rowType := slice.Type().Elem() // todo: should support pointer type
+	k := reflect.ValueOf(key).Convert(dest.Type().Key())
+	if dest.MapIndex(k).IsValid() {
+		return fmt.Errorf("duplicate key '%s' at row %d in table '%s' (inside '%s' section)", key, rowNum, t.fieldName, label)
+	}
+	firstRows[key] = rowNum
+	dest.SetMapIndex(k, row)
+	return nil
+}
+
+// Transpose makes this table vertical key/value table like "Property | Value"
+//
+// The first column is matched against keys of [Table.Field] (aliases are available)
+// and the second column is assigned into the field of a struct, not into a slice of rows.
+// Use "." as the field name of [Layout.Table] to fill the section struct itself:
+//
+//	settings := layout.Table(".").Transpose()
+//	settings.Field("MaxRows", "Max Rows").Required()
+//
+// Undeclared keys are ignored (or collected by [Table.Extra], reported by [Table.Strict]).
+func (t *Table[T]) Transpose() *Table[T] {
+	t.j.invalidate()
+	t.transpose = true
+	return t
+}
 
-	var fieldKeys []string
+func (t Table[T]) assignCellsTransposed(target reflect.Value, rows [][]string, label string, doc *T) error {
+	dest := t.ref.field(target)
+	if dest.Kind() == reflect.Pointer {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	}
+	if dest.Kind() != reflect.Struct {
+		return fmt.Errorf("field '%s' of %s is not struct type (inside '%s' section)", t.fieldName, target.Type(), label)
+	}
 	for _, f := range t.fields {
-		fieldKeys = append(fieldKeys, f.key)
+		if !f.ref.valid {
+			return fmt.Errorf("%s doesn't have field '%s' (inside '%s' section)", dest.Type(), f.fieldName, label)
+		}
+	}
+	var extra reflect.Value
+	if t.extra != "" {
+		extra = t.extraRef.field(dest)
+		if !extra.IsValid() || extra.Kind() != reflect.Map || extra.Type().Key().Kind() != reflect.String || extra.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("extra field '%s' of %s should be map[string]string (inside '%s' section)", t.extra, dest.Type(), label)
+		}
 	}
 
-	keyMap := make([]string, len(t.fields))
-	usedKeys := make(map[string]bool)
-	for k := range key2column {
-		index, k2, ok := t.j.translateToPrimaryKey(k, fieldKeys)
-		if ok {
-			keyMap[index] = k
-			usedKeys[strings.ToLower(k2)] = true
-		} else {
-			lk := strings.ToLower(k)
-			for i, f := range t.fields {
-				if lk == f.key {
-					keyMap[i] = lk
-					usedKeys[lk] = true
-					break
+	found := make([]int, len(t.fields)) // row number
+	var unknown []string
+	for ri, rv := range rows {
+		if len(rv) < 2 {
+			return fmt.Errorf("transposed table '%s' should have two columns (inside '%s' section)", t.fieldName, label)
+		}
+		key, value := rv[0], rv[1]
+		fi, ok := t.columns[strings.ToLower(key)]
+		if !ok {
+			if extra.IsValid() {
+				if extra.IsNil() {
+					extra.Set(reflect.MakeMap(extra.Type()))
 				}
+				extra.SetMapIndex(reflect.ValueOf(key).Convert(extra.Type().Key()), reflect.ValueOf(value).Convert(extra.Type().Elem()))
+			} else {
+				unknown = append(unknown, key)
 			}
+			continue
+		}
+		f := t.fields[fi]
+		if found[fi] != 0 {
+			return fmt.Errorf("duplicate key '%s' at row %d (first at row %d) in table '%s' (inside '%s' section)", key, ri+1, found[fi], t.fieldName, label)
+		}
+		found[fi] = ri + 1
+		var err error
+		if f.convert != nil {
+			var newV any
+			newV, err = f.convert(value, doc)
+			if err == nil {
+				err = setConverted(f.ref.field(dest), newV)
+			}
+		} else if value != "" || f.blankBool {
+			err = f.ref.set(f.ref.field(dest), value)
+		}
+		if err != nil {
+			return fmt.Errorf("can't convert value '%s' at row %d, key '%s' of table '%s' (inside '%s' section): %w", value, ri+1, key, t.fieldName, label, err)
+		}
+	}
+	if t.strict && len(unknown) > 0 {
+		return fmt.Errorf("unknown key(%s) in table '%s' (inside '%s' section)", strings.Join(unknown, ", "), t.fieldName, label)
+	}
+	var missing []string
+	for fi, f := range t.fields {
+		if f.required && found[fi] == 0 {
+			missing = append(missing, f.origKey)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required key(%s) are missing (inside '%s' section)", strings.Join(missing, ", "), label)
+	}
+	return nil
+}
+
+// Strict reports undeclared columns as error. It doesn't affect tables with [Table.Extra] or [Table.AsMap].
+func (t *Table[T]) Strict() *Table[T] {
+	t.strict = true
+	return t
+}
+
+func (t Table[T]) assignCells(target reflect.Value, headers []string, rows [][]string, label string, doc *T) error {
+	if t.transpose {
+		return t.assignCellsTransposed(target, rows, label, doc)
+	}
+	if t.asMap {
+		return t.assignCellsAsMap(target, headers, rows, label, doc)
+	} else {
+		return t.assignCellsAsStruct(target, headers, rows, label, doc)
+	}
+}
+
+func (t Table[T]) assignCellsAsStruct(target reflect.Value, headers []string, rows [][]string, label string, doc *T) error {
+	dest, keyColumn, skip, err := t.destination(target, headers, label)
+	if err != nil || skip {
+		return err
+	}
+	rowType := t.rowType // todo: should support pointer type for slice
+
+	// field index to column index
+	columnMap := make([]int, len(t.fields))
+	for i := range columnMap {
+		columnMap[i] = -1
+	}
+	for c, h := range headers {
+		if fi, ok := t.columns[strings.ToLower(h)]; ok && columnMap[fi] == -1 {
+			columnMap[fi] = c
 		}
 	}
 
 	var missingField []string
-	for _, f := range t.fields {
-		if f.required {
-			if _, ok := usedKeys[f.key]; !ok {
-				missingField = append(missingField, f.origKey)
-			}
+	for fi, f := range t.fields {
+		if f.required && columnMap[fi] == -1 {
+			missingField = append(missingField, f.origKey)
 		}
 	}
 
@@ -92,83 +303,237 @@ func (t Table[T]) assignCellsAsStruct(target reflect.Value, cells []map[string]s
 		return fmt.Errorf("required column(%s) are missing (inside '%s' section)", strings.Join(missingField, ", "), label)
 	}
 
-	for _, rv := range cells {
-		row := reflect.New(rowType).Elem()
+	for _, f := range t.fields {
+		if !f.ref.valid {
+			return fmt.Errorf("%s doesn't have field '%s' (inside '%s' section)", rowType, f.fieldName, label)
+		}
+	}
+
+	var groups []*FieldGroup[T]
+	var groupColumns [][]int
+	for _, item := range t.items {
+		g := item.group
+		if g == nil {
+			continue
+		}
+		if !g.ref.valid {
+			return fmt.Errorf("%s doesn't have field '%s' (inside '%s' section)", rowType, g.fieldName, label)
+		}
+		if g.fieldErr != "" {
+			return fmt.Errorf("%s (inside '%s' section)", g.fieldErr, label)
+		}
+		groups = append(groups, g)
+		groupColumns = append(groupColumns, g.columnIndexes(headers))
+	}
+
+	// undeclared columns
+	known := make([]bool, len(headers))
+	for _, c := range columnMap {
+		if c != -1 {
+			known[c] = true
+		}
+	}
+	for _, gc := range groupColumns {
+		for _, c := range gc {
+			if c != -1 {
+				known[c] = true
+			}
+		}
+	}
+	var unknown []int
+	for c, ok := range known {
+		if !ok {
+			unknown = append(unknown, c)
+		}
+	}
+	if t.extra != "" {
+		if ft := t.extraRef.typ(rowType); ft == nil || ft.Kind() != reflect.Map || ft.Key().Kind() != reflect.String || ft.Elem().Kind() != reflect.String {
+			return fmt.Errorf("extra field '%s' of %s should be map[string]string (inside '%s' section)", t.extra, rowType, label)
+		}
+	} else if t.strict && len(unknown) > 0 {
+		names := make([]string, len(unknown))
+		for i, c := range unknown {
+			names[i] = headers[c]
+		}
+		return fmt.Errorf("unknown column(%s) in table '%s' (inside '%s' section)", strings.Join(names, ", "), t.fieldName, label)
+	}
+
+	newSlice := dest
+	if keyColumn != -1 {
+		newSlice = reflect.MakeSlice(reflect.SliceOf(rowType), 0, len(rows))
+	}
+	for ri, rv := range rows {
+		newSlice = reflect.Append(newSlice, reflect.Zero(rowType))
+		row := newSlice.Index(newSlice.Len() - 1)
 		for fi, f := range t.fields {
-			var cv any = rv[keyMap[fi]]
-			ct := row.FieldByName(f.fieldName)
-			if !ct.IsValid() {
-				return fmt.Errorf("%s doesn't have field '%s' (inside '%s' section)", rowType, f.fieldName, label)
+			c := columnMap[fi]
+			if c == -1 {
+				if f.convert == nil {
+					continue
+				}
+				// converter receives blank for missing column to fill default value
+				newV, err := f.convert("", doc)
+				if err == nil {
+					err = setConverted(f.ref.field(row), newV)
+				}
+				if err != nil {
+					return fmt.Errorf("can't convert value '' at row %d, column '%s' of table '%s' (inside '%s' section): %w", ri+1, f.origKey, t.fieldName, label, err)
+				}
+				continue
 			}
+			cell := rv[c]
+			var err error
 			if f.convert != nil {
-				newV, err := f.convert(rv[keyMap[fi]], doc)
-				if err != nil {
-					return fmt.Errorf("can't convert value '%s' at field '%s' (inside '%s' section): %w", rv[keyMap[fi]], f.origKey, label, err)
+				var newV any
+				newV, err = f.convert(cell, doc)
+				if err == nil {
+					err = setConverted(f.ref.field(row), newV)
 				}
-				runtimescan.FuzzyAssign(ct, newV)
-			} else {
-				runtimescan.FuzzyAssign(ct.Addr().Interface(), cv)
+			} else if cell != "" || f.blankBool {
+				// blank cell is zero value except bool (nested struct pointers are not allocated)
+				err = f.ref.set(f.ref.field(row), cell)
+			}
+			if err != nil {
+				return fmt.Errorf("can't convert value '%s' at row %d, column '%s' of table '%s' (inside '%s' section): %w", cell, ri+1, headers[c], t.fieldName, label, err)
+			}
+		}
+		for gi, g := range groups {
+			if c, err := g.assign(g.ref.field(row), groupColumns[gi], rv); err != nil {
+				return fmt.Errorf("can't convert value '%s' at row %d, column '%s' of table '%s' (inside '%s' section): %w", rv[c], ri+1, headers[c], t.fieldName, label, err)
 			}
 		}
-		slice = reflect.Append(slice, row)
+		if t.extra != "" && len(unknown) > 0 {
+			field := t.extraRef.field(row)
+			extra := reflect.MakeMapWithSize(field.Type(), len(unknown))
+			for _, c := range unknown {
+				extra.SetMapIndex(reflect.ValueOf(headers[c]).Convert(field.Type().Key()), reflect.ValueOf(rv[c]).Convert(field.Type().Elem()))
+			}
+			field.Set(extra)
+		}
+	}
+	if keyColumn == -1 {
+		dest.Set(newSlice)
+		return nil
+	}
+	firstRows := make(map[string]int, len(rows))
+	for ri, rv := range rows {
+		row := newSlice.Index(ri)
+		if t.rowPtr {
+			row = row.Addr()
+		}
+		if err := t.storeByKey(dest, rv[keyColumn], row, ri+1, firstRows, label); err != nil {
+			return err
+		}
 	}
-	sliceTarget := target.FieldByName(t.fieldName)
-	sliceTarget.Set(slice)
 	return nil
 }
 
-func (t Table[T]) assignCellsAsMap(target reflect.Value, cells []map[string]string, key2column map[string]int, label string, doc *T) error {
-	var slice reflect.Value
-	if target.Kind() == reflect.Pointer { // for repeat
-		slice = target.Elem().FieldByName(t.fieldName)
-	} else {
-		slice = target.FieldByName(t.fieldName)
-	}
-	if slice.Kind() != reflect.Slice {
-		return fmt.Errorf("field '%s' of %s is not slice type (inside '%s' section)", t.fieldName, target.Type(), label)
+func (t Table[T]) assignCellsAsMap(target reflect.Value, headers []string, rows [][]string, label string, doc *T) error {
+	dest, keyColumn, skip, err := t.destination(target, headers, label)
+	if err != nil || skip {
+		return err
 	}
 
-	usedKeys := make([]string, len(key2column))
-	origKeyMap := make([]string, len(key2column))
-	for k, v := range key2column {
-		usedKeys[v] = strings.ToLower(k)
-		origKeyMap[v] = k
+	keys := t.j.newRecordKeys(headers)
+	useRecord := t.rowType == recordType
+
+	var result []map[string]string
+	var records []Record
+	for _, rv := range rows {
+		if useRecord {
+			records = append(records, Record{keys: keys, values: rv})
+			continue
+		}
+		row := make(map[string]string, len(headers))
+		for i, k := range keys.headers {
+			row[k] = rv[i]
+		}
+		result = append(result, row)
 	}
-	keyMap := make([]string, len(key2column))
-	for i, shortK := range usedKeys {
-		index, key, ok := t.j.translateToPrimaryKey(shortK, usedKeys)
-		if ok {
-			keyMap[index] = shortK
-			origKeyMap[index] = key
+	if keyColumn == -1 {
+		values := reflect.ValueOf(result)
+		if useRecord {
+			values = reflect.ValueOf(records)
+		}
+		dest.Set(reflect.AppendSlice(dest, values))
+		return nil
+	}
+	firstRows := make(map[string]int, len(rows))
+	for ri, rv := range rows {
+		var row reflect.Value
+		if useRecord {
+			row = reflect.ValueOf(records[ri])
 		} else {
-			keyMap[i] = shortK
+			row = reflect.ValueOf(result[ri])
+		}
+		if err := t.storeByKey(dest, rv[keyColumn], row, ri+1, firstRows, label); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	var result []map[string]string
-	for _, rv := range cells {
-		row := make(map[string]string)
-		for ki, key := range keyMap {
-			row[origKeyMap[ki]] = rv[key]
+func (t Table[T]) boolWord(f *StructField[T], value bool, lang string) (string, bool) {
+	if f.bools != nil {
+		return f.bools.word(value, lang)
+	}
+	return t.j.bools.word(value, lang)
+}
+
+// headerLine returns header of CSV/TSV code fence for template
+func (t Table[T]) headerLine(format dataFormat, lang string) string {
+	if lang == "" {
+		lang = t.j.DefaultLang
+	}
+	var headers []string
+	for _, item := range t.items {
+		if g := item.group; g != nil {
+			for _, c := range g.columns {
+				headers = append(headers, t.j.findTranslation(c, lang))
+			}
+		} else {
+			headers = append(headers, t.j.findTranslation(item.field.origKey, lang))
 		}
-		result = append(result, row)
 	}
-	slice = reflect.ValueOf(result)
-	if target.Kind() == reflect.Pointer { // for repeat
-		sliceTarget := target.Elem().FieldByName(t.fieldName)
-		sliceTarget.Set(slice)
-	} else {
-		sliceTarget := target.FieldByName(t.fieldName)
-		sliceTarget.Set(slice)
+	if format == dataTSV {
+		return strings.Join(headers, "\t")
 	}
-	return nil
+	return strings.Join(headers, ",")
 }
 
 func (t Table[T]) generateTemplate(w io.Writer, lang string) {
+	if lang == "" {
+		lang = t.j.DefaultLang
+	}
+	if t.transpose {
+		cells := [][]any{{"Property", "Value"}}
+		for _, f := range t.fields {
+			var v any = "..."
+			if len(f.samples) > 0 {
+				v = f.samples[0]
+			}
+			cells = append(cells, []any{t.j.findTranslation(f.origKey, lang), v})
+		}
+		formatdata.FormatDataTo(cells, w, formatdata.Opt{
+			OutputFormat: formatdata.Markdown,
+		})
+		io.WriteString(w, "\n")
+		return
+	}
 	maxRows := 2
-	headers := make([]any, len(t.fields))
-	for i, f := range t.fields {
-		headers[i] = t.j.findTranslation(f.fieldName, lang)
+	var headers []any
+	for _, item := range t.items {
+		if g := item.group; g != nil {
+			for _, c := range g.columns {
+				headers = append(headers, t.j.findTranslation(c, lang))
+			}
+			if len(g.samples) > maxRows {
+				maxRows = len(g.samples)
+			}
+			continue
+		}
+		f := item.field
+		headers = append(headers, t.j.findTranslation(f.origKey, lang))
 		if len(f.samples) > maxRows {
 			maxRows = len(f.samples)
 		}
@@ -176,12 +541,31 @@ func (t Table[T]) generateTemplate(w io.Writer, lang string) {
 	cells := make([][]any, maxRows+1)
 	cells[0] = headers
 	for i := 0; i < maxRows; i++ {
-		row := make([]any, len(t.fields))
-		for j, f := range t.fields {
+		row := make([]any, 0, len(headers))
+		for _, item := range t.items {
+			if g := item.group; g != nil {
+				if i < len(g.samples) {
+					for _, m := range g.sampleMarks(g.samples[i]) {
+						row = append(row, g.sampleWord(m, lang))
+					}
+				} else {
+					for range g.columns {
+						row = append(row, "...")
+					}
+				}
+				continue
+			}
+			f := item.field
 			if i < len(f.samples) {
-				row[j] = f.samples[i]
+				var v any = f.samples[i]
+				if b, ok := f.samples[i].(bool); ok {
+					if w, ok := t.boolWord(f, b, lang); ok {
+						v = w
+					}
+				}
+				row = append(row, v)
 			} else {
-				row[j] = "..."
+				row = append(row, "...")
 			}
 		}
 		cells[i+1] = row
@@ -3,6 +3,7 @@ package mdd
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -22,6 +23,8 @@ func TestTable(t *testing.T) {
 	type Row struct {
 		StringCell  string
 		IntCell     int
+		Int8Cell    int8
+		Uint8Cell   uint8
 		DefinedType DefinedType
 		BoolCell    bool
 		CustomCell  CustomCell
@@ -269,6 +272,149 @@ func TestTable(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "blank cell is zero value",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					table := root.Table("TableContent")
+					table.Field("StringCell")
+					table.Field("IntCell")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| StringCell | IntCell |
+				|------------|---------|
+				| hello      |         |
+				`),
+			},
+			want: &Doc{
+				TableContent: []Row{
+					{StringCell: "hello"},
+				},
+			},
+		},
+		{
+			name: "invalid cell",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					table := root.Table("TableContent")
+					table.Field("StringCell")
+					table.Field("IntCell", "Count")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| StringCell | count |
+				|------------|-------|
+				| hello      | 5     |
+				| world!!    | abc   |
+				`),
+			},
+			wantErr: "can't convert value 'abc' at row 2, column 'count' of table 'TableContent' (inside 'Root Heading' section): strconv.ParseInt: parsing \"abc\": invalid syntax",
+		},
+		{
+			name: "overflow cell",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					table := root.Table("TableContent")
+					table.Field("Int8Cell", "Small")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Small |
+				|-------|
+				| 127   |
+				| 300   |
+				`),
+			},
+			wantErr: "can't convert value '300' at row 2, column 'Small' of table 'TableContent' (inside 'Root Heading' section): strconv.ParseInt: parsing \"300\": value out of range",
+		},
+		{
+			name: "overflow unsigned cell",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					table := root.Table("TableContent")
+					table.Field("Uint8Cell", "Byte")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Byte |
+				|------|
+				| 256  |
+				`),
+			},
+			wantErr: "can't convert value '256' at row 1, column 'Byte' of table 'TableContent' (inside 'Root Heading' section): strconv.ParseUint: parsing \"256\": value out of range",
+		},
+		{
+			name: "custom cell (missing column)",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					table := root.Table("TableContent")
+					table.Field("StringCell")
+					table.Field("CustomCell", "KeyValue").As(func(v string, d *Doc) (any, error) {
+						if v == "" {
+							return CustomCell{Name: "default"}, nil
+						}
+						return nil, fmt.Errorf("unexpected value '%s'", v)
+					})
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| StringCell |
+				|------------|
+				| hello      |
+				`),
+			},
+			want: &Doc{
+				TableContent: []Row{
+					{
+						StringCell: "hello",
+						CustomCell: CustomCell{Name: "default"},
+					},
+				},
+			},
+		},
+		{
+			name: "custom cell (type mismatch)",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					root := jig.Root()
+					table := root.Table("TableContent")
+					table.Field("CustomCell", "KeyValue").As(func(v string, d *Doc) (any, error) {
+						return v, nil
+					})
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| KeyValue |
+				|----------|
+				| length:5 |
+				`),
+			},
+			wantErr: "can't convert value 'length:5' at row 1, column 'KeyValue' of table 'TableContent' (inside 'Root Heading' section): converted value type string is not assignable to mdd.CustomCell",
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -524,3 +670,563 @@ func TestTable_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestTable_Split(t *testing.T) {
+	type Row struct {
+		Name    string
+		Columns []string
+		Lines   []string
+		IDs     []int
+		Tags    []string
+	}
+
+	type Doc struct {
+		Rows []Row
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "separators",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows")
+					table.Field("Name")
+					table.Field("Columns").Split(",").Trim("`")
+					table.Field("Lines").Split("<br>")
+					table.Field("IDs").Split(",")
+					table.Field("Tags").SplitRegexp(regexp.MustCompile(`[;/]`))
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Name  | Columns                   | Lines      | IDs      | Tags          |
+				|-------|---------------------------|------------|----------|---------------|
+				| users | `+"`user_id`, name, email,"+` | a<br>b<br/>c | 1, 2, 3  | red; blue/green |
+				| empty |                           |            |          |               |
+				`),
+			},
+			want: &Doc{
+				Rows: []Row{
+					{
+						Name:    "users",
+						Columns: []string{"user_id", "name", "email"},
+						Lines:   []string{"a", "b", "c"},
+						IDs:     []int{1, 2, 3},
+						Tags:    []string{"red", "blue", "green"},
+					},
+					{
+						Name: "empty",
+					},
+				},
+			},
+		},
+		{
+			name: "keep space",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows")
+					table.Field("Columns").Split(",").KeepSpace()
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Columns |
+				|---------|
+				| a, ,b   |
+				`),
+			},
+			want: &Doc{
+				Rows: []Row{
+					{Columns: []string{"a", " ", "b"}},
+				},
+			},
+		},
+		{
+			name: "invalid element",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows")
+					table.Field("IDs").Split(",")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| IDs  |
+				|------|
+				| 1, x |
+				`),
+			},
+			wantErr: "can't convert value '1, x' at row 1, column 'IDs' of table 'Rows' (inside 'Root Heading' section): element 2: strconv.ParseInt: parsing \"x\": invalid syntax",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTable_Extra(t *testing.T) {
+	type Row struct {
+		Table string
+		Extra map[string]string
+	}
+
+	type Doc struct {
+		Rows []Row
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "collect extra columns",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").Extra("Extra")
+					table.Field("Table")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  | Owner | Added At   |
+				|--------|-------|------------|
+				| users  | bob   | 2024-01-01 |
+				| groups |       |            |
+				`),
+			},
+			want: &Doc{
+				Rows: []Row{
+					{Table: "users", Extra: map[string]string{"Owner": "bob", "Added At": "2024-01-01"}},
+					{Table: "groups", Extra: map[string]string{"Owner": "", "Added At": ""}},
+				},
+			},
+		},
+		{
+			name: "no extra columns",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").Extra("Extra").Strict()
+					table.Field("Table")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  |
+				|--------|
+				| users  |
+				`),
+			},
+			want: &Doc{
+				Rows: []Row{
+					{Table: "users"},
+				},
+			},
+		},
+		{
+			name: "strict",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").Strict()
+					table.Field("Table")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  | Owner | Added At   |
+				|--------|-------|------------|
+				| users  | bob   | 2024-01-01 |
+				`),
+			},
+			wantErr: "unknown column(Owner, Added At) in table 'Rows' (inside 'Root Heading' section)",
+		},
+		{
+			name: "invalid extra field",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").Extra("Table")
+					table.Field("Table")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  |
+				|--------|
+				| users  |
+				`),
+			},
+			wantErr: "extra field 'Table' of mdd.Row should be map[string]string (inside 'Root Heading' section)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTable_KeyBy(t *testing.T) {
+	type Row struct {
+		Table string
+		R     bool
+	}
+
+	type Doc struct {
+		Rows    map[string]Row
+		RowPtrs map[string]*Row
+		Maps    map[string]map[string]string
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "struct rows",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").KeyBy("Table")
+					table.Field("Table")
+					table.Field("R")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  | R |
+				|--------|---|
+				| users  | X |
+				| groups |   |
+				`),
+			},
+			want: &Doc{
+				Rows: map[string]Row{
+					"users":  {Table: "users", R: true},
+					"groups": {Table: "groups"},
+				},
+			},
+		},
+		{
+			name: "pointer rows with alias",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Alias("Table", "表")
+					table := jig.Root().Table("RowPtrs").KeyBy("Table")
+					table.Field("Table")
+					table.Field("R")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| 表     | R |
+				|--------|---|
+				| users  | X |
+				`),
+			},
+			want: &Doc{
+				RowPtrs: map[string]*Row{
+					"users": {Table: "users", R: true},
+				},
+			},
+		},
+		{
+			name: "as map",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Root().Table("Maps").AsMap().KeyBy("Table")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  | R |
+				|--------|---|
+				| users  | X |
+				`),
+			},
+			want: &Doc{
+				Maps: map[string]map[string]string{
+					"users": {"Table": "users", "R": "X"},
+				},
+			},
+		},
+		{
+			name: "duplicate key",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").KeyBy("Table")
+					table.Field("Table")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| Table  |
+				|--------|
+				| users  |
+				| groups |
+				| users  |
+				`),
+			},
+			wantErr: "duplicate key 'users' at row 3 (first at row 1) in table 'Rows' (inside 'Root Heading' section)",
+		},
+		{
+			name: "missing key column",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Rows").KeyBy("Table")
+					table.Field("R")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Root Heading
+
+				| R |
+				|---|
+				| X |
+				`),
+			},
+			wantErr: "key column(Table) is missing in table 'Rows' (inside 'Root Heading' section)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTable_Transpose(t *testing.T) {
+	type Settings struct {
+		Timeout int
+	}
+
+	type Doc struct {
+		Name     string
+		MaxRows  int
+		ReadOnly bool
+		Settings *Settings
+		Others   map[string]string
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "section struct",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					jig.Alias("Max Rows").Lang("ja", "最大行数")
+					root := jig.Root()
+					root.Label("Name")
+					table := root.Table(".").Transpose().Extra("Others")
+					table.Field("MaxRows", "Max Rows").Required()
+					table.Field("ReadOnly", "Read Only")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Settings
+
+				| Property  | Value |
+				|-----------|-------|
+				| 最大行数  | 100   |
+				| read only | yes   |
+				| Owner     | bob   |
+				`),
+			},
+			want: &Doc{
+				Name:     "Settings",
+				MaxRows:  100,
+				ReadOnly: true,
+				Others:   map[string]string{"Owner": "bob"},
+			},
+		},
+		{
+			name: "nested struct",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table("Settings").Transpose()
+					table.Field("Timeout")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Settings
+
+				| Property | Value |
+				|----------|-------|
+				| Timeout  | 30    |
+				| Owner    | bob   |
+				`),
+			},
+			want: &Doc{
+				Settings: &Settings{Timeout: 30},
+			},
+		},
+		{
+			name: "required",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table(".").Transpose()
+					table.Field("MaxRows", "Max Rows").Required()
+					table.Field("ReadOnly", "Read Only")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Settings
+
+				| Property  | Value |
+				|-----------|-------|
+				| Read Only | yes   |
+				`),
+			},
+			wantErr: "required key(Max Rows) are missing (inside 'Settings' section)",
+		},
+		{
+			name: "strict and invalid value",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table(".").Transpose().Strict()
+					table.Field("MaxRows", "Max Rows")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Settings
+
+				| Property | Value |
+				|----------|-------|
+				| Max Rows | many  |
+				`),
+			},
+			wantErr: "can't convert value 'many' at row 1, key 'Max Rows' of table '.' (inside 'Settings' section): strconv.ParseInt: parsing \"many\": invalid syntax",
+		},
+		{
+			name: "duplicate key",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJig[Doc]()
+					table := jig.Root().Table(".").Transpose().Strict()
+					table.Field("MaxRows", "Max Rows")
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Settings
+
+				| Property | Value |
+				|----------|-------|
+				| Max Rows | 1     |
+				| max rows | 2     |
+				`),
+			},
+			wantErr: "duplicate key 'max rows' at row 2 (first at row 1) in table '.' (inside 'Settings' section)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTable_TransposeGenerate(t *testing.T) {
+	type Doc struct {
+		MaxRows  int
+		ReadOnly bool
+	}
+
+	jig := NewDocJig[Doc]()
+	table := jig.Root().Table(".").Transpose()
+	table.Field("MaxRows", "Max Rows").Samples(100)
+	table.Field("ReadOnly", "Read Only")
+
+	var b bytes.Buffer
+	assert.NoError(t, jig.GenerateTemplate(&b))
+	assert.Equal(t, TrimIndent(t, `
+	# [Title]
+
+	| Property  | Value |
+	|-----------|-------|
+	| Max Rows  | 100   |
+	| Read Only | ...   |
+	`), strings.TrimRight(b.String(), "\n"))
+}
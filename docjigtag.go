@@ -0,0 +1,266 @@
+package mdd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NewDocJigFromTags creates [DocJig] from struct tags of T
+//
+// It derives same definition as [Layout]'s methods from `mdd` tags:
+//
+//	type SQLDoc struct {
+//	    Name       string       `mdd:"label"`
+//	    SQL        string       `mdd:"fence=sql"`
+//	    CRUDMatrix []CRUDMatrix `mdd:"child=CRUD Matrix,table"`
+//	}
+//
+//	type CRUDMatrix struct {
+//	    Table       string `mdd:"column,required"`
+//	    Description string `mdd:"column=Desc"`
+//	}
+//
+// Supported tags for document structs:
+//
+//	label[=pattern]          Layout.Label(field, pattern)
+//	option[=pattern]         Layout.Option(field, pattern)
+//	fence[=lang|lang...]     Layout.CodeFence(field, langs...)
+//	  lang=Field, info=Field   CodeFence.Language(Field), CodeFence.Info(Field)
+//	  attr=name:Field|...      CodeFence.Attr(name, Field)
+//	  format=csv|tsv|json|yaml CodeFence.CSV() (columns from row struct tags), TSV(), JSON(), YAML()
+//	child=pattern            Layout.Child(field, pattern) (Layout.Children if field is slice)
+//	children=pattern         Layout.Children(field, pattern)
+//	table                    Layout.Table(field). With child/children, table is in Child(".", pattern)
+//	  key=column               Table.KeyBy(column) (field should be map)
+//
+// Supported tags for table row structs (all exported fields are columns if no tag):
+//
+//	column[=key]             Table.Field(field, key)
+//	required                 StructField.Required()
+//	alias=label|label...     DocJig.Alias(key, labels...)
+//	group=column|column...   Table.FieldGroup(field, columns...)
+//	extra                    Table.Extra(field) (field should be map[string]string)
+//	-                        Skip this field
+//
+// Returned jig can be refined by builder methods. Calling same builder method
+// with same field name (and same pattern, languages or key if given) returns the existing definition:
+//
+//	jig := NewDocJigFromTags[SQLDoc]()
+//	jig.Root().Child(".", "CRUD Matrix").Table("CRUDMatrix").Field("Table").Samples("users")
+//
+// It panics if tags are invalid.
+func NewDocJigFromTags[T any]() *DocJig[T] {
+	j := NewDocJig[T]()
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if err := buildLayoutFromTags(j.root, indirectType(t)); err != nil {
+		panic(err)
+	}
+	return j
+}
+
+type tagEntry struct {
+	key   string
+	value string
+	set   bool
+}
+
+type mddTag []tagEntry
+
+func parseMddTag(tag string) mddTag {
+	var result mddTag
+	for _, e := range strings.Split(tag, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(e, "=")
+		result = append(result, tagEntry{
+			key:   strings.TrimSpace(k),
+			value: strings.TrimSpace(v),
+			set:   ok,
+		})
+	}
+	return result
+}
+
+func (t mddTag) get(key string) (value string, ok bool) {
+	for _, e := range t {
+		if e.key == key {
+			return e.value, true
+		}
+	}
+	return "", false
+}
+
+func (t mddTag) patterns(key string) []string {
+	for _, e := range t {
+		if e.key == key && e.set {
+			return []string{e.value}
+		}
+	}
+	return nil
+}
+
+func buildLayoutFromTags[T any](l *Layout[T], t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("mdd tag: %s is not struct", t)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tagStr, hasTag := f.Tag.Lookup("mdd")
+		if f.Anonymous && !hasTag {
+			// fields of embedded struct are promoted to this layout
+			if et := indirectType(f.Type); et.Kind() == reflect.Struct {
+				if err := buildLayoutFromTags(l, et); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !hasTag || tagStr == "-" || !f.IsExported() {
+			continue
+		}
+		tag := parseMddTag(tagStr)
+		if _, ok := tag.get("label"); ok {
+			l.Label(f.Name, tag.patterns("label")...)
+		}
+		if _, ok := tag.get("option"); ok {
+			l.Option(f.Name, tag.patterns("option")...)
+		}
+		if langs, ok := tag.get("fence"); ok {
+			var targetLanguages []string
+			if langs != "" {
+				targetLanguages = strings.Split(langs, "|")
+			}
+			cf := l.CodeFence(f.Name, targetLanguages...)
+			if lang, ok := tag.get("lang"); ok {
+				cf.Language(lang)
+			}
+			if info, ok := tag.get("info"); ok {
+				cf.Info(info)
+			}
+			if attrs, ok := tag.get("attr"); ok && attrs != "" {
+				for _, a := range strings.Split(attrs, "|") {
+					name, field, ok := strings.Cut(a, ":")
+					if !ok {
+						return fmt.Errorf("mdd tag: attr of field '%s' should be name:Field, but '%s'", f.Name, a)
+					}
+					cf.Attr(strings.TrimSpace(name), strings.TrimSpace(field))
+				}
+			}
+			if format, ok := tag.get("format"); ok {
+				var err error
+				switch format {
+				case "csv":
+					err = buildTableFromTags(cf.CSV(), f)
+				case "tsv":
+					err = buildTableFromTags(cf.TSV(), f)
+				case "json":
+					cf.JSON()
+				case "yaml":
+					cf.YAML()
+				default:
+					err = fmt.Errorf("mdd tag: unknown format '%s' of field '%s' (csv, tsv, json and yaml are available)", format, f.Name)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, isTable := tag.get("table")
+		childPattern, isChild := tag.get("child")
+		childrenPattern, isChildren := tag.get("children")
+		if isChildren {
+			childPattern = childrenPattern
+		}
+		ft := indirectType(f.Type)
+		switch {
+		case (isChild || isChildren) && isTable:
+			var child *Layout[T]
+			if isChildren {
+				child = l.Children(".", childPattern)
+			} else {
+				child = l.Child(".", childPattern)
+			}
+			if err := buildTableFromTags(child.Table(f.Name), f); err != nil {
+				return err
+			}
+		case isChild || isChildren:
+			if ft.Kind() == reflect.Slice {
+				isChildren = true
+				ft = indirectType(ft.Elem())
+			}
+			var child *Layout[T]
+			if isChildren {
+				child = l.Children(f.Name, childPattern)
+			} else {
+				child = l.Child(f.Name, childPattern)
+			}
+			if err := buildLayoutFromTags(child, ft); err != nil {
+				return err
+			}
+		case isTable:
+			if err := buildTableFromTags(l.Table(f.Name), f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func buildTableFromTags[T any](table *Table[T], f reflect.StructField) error {
+	ft := indirectType(f.Type)
+	if key, ok := parseMddTag(f.Tag.Get("mdd")).get("key"); ok && key != "" {
+		if ft.Kind() != reflect.Map {
+			return fmt.Errorf("mdd tag: table field '%s' with key should be map, but %s", f.Name, f.Type)
+		}
+		table.KeyBy(key)
+	} else if ft.Kind() != reflect.Slice {
+		return fmt.Errorf("mdd tag: table field '%s' should be slice, but %s", f.Name, f.Type)
+	}
+	rowType := indirectType(ft.Elem())
+	if rowType == recordType {
+		table.AsMap()
+		return nil
+	}
+	switch rowType.Kind() {
+	case reflect.Map:
+		table.AsMap()
+		return nil
+	case reflect.Struct:
+	default:
+		return fmt.Errorf("mdd tag: row type of table field '%s' should be struct or map, but %s", f.Name, rowType)
+	}
+	for i := 0; i < rowType.NumField(); i++ {
+		c := rowType.Field(i)
+		tagStr := c.Tag.Get("mdd")
+		if tagStr == "-" || !c.IsExported() {
+			continue
+		}
+		tag := parseMddTag(tagStr)
+		if _, ok := tag.get("extra"); ok {
+			table.Extra(c.Name)
+			continue
+		}
+		if columns, ok := tag.get("group"); ok {
+			table.FieldGroup(c.Name, strings.Split(columns, "|")...)
+			continue
+		}
+		key := c.Name
+		var field *StructField[T]
+		if k := tag.patterns("column"); len(k) > 0 && k[0] != "" {
+			key = k[0]
+			field = table.Field(c.Name, key)
+		} else {
+			field = table.Field(c.Name)
+		}
+		if _, ok := tag.get("required"); ok {
+			field.Required()
+		}
+		if aliases, ok := tag.get("alias"); ok && aliases != "" {
+			table.j.Alias(key, strings.Split(aliases, "|")...)
+		}
+	}
+	return nil
+}
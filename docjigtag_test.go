@@ -0,0 +1,193 @@
+package mdd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDocJigFromTags(t *testing.T) {
+	type CRUD struct {
+		Table       string `mdd:"column,required"`
+		C           bool
+		R           bool
+		U           bool
+		D           bool
+		Description string `mdd:"column=Desc,alias=Detail|詳細"`
+		Ignored     string `mdd:"-"`
+	}
+
+	type Query struct {
+		Name string `mdd:"label"`
+		SQL  string `mdd:"fence=sql,lang=Lang,info=Info"`
+		Lang string
+		Info string
+		Safe bool `mdd:"option"`
+	}
+
+	type Common struct {
+		Memo string `mdd:"fence=txt"`
+	}
+
+	type Doc struct {
+		Common
+		Name    string              `mdd:"label"`
+		CRUD    []CRUD              `mdd:"child=CRUD Matrix,table"`
+		Queries []Query             `mdd:"child=Query"`
+		Params  []map[string]string `mdd:"child=Parameters,table"`
+	}
+
+	type args struct {
+		create func(t *testing.T) *DocJig[Doc]
+		src    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Doc
+		wantErr string
+	}{
+		{
+			name: "tags",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					return NewDocJigFromTags[Doc]()
+				},
+				src: TrimIndent(t, `
+				# Find User
+
+				~~~txt
+				memo
+				~~~
+
+				## CRUD Matrix
+
+				| Table | C | R | U | D | 詳細            |
+				|-------|---|---|---|---|-----------------|
+				| users |   | X |   |   | name is indexed |
+
+				## Query: by name (Safe)
+
+				~~~sql :find
+				select * from users where name = /*name*/'bob';
+				~~~
+
+				## Parameters
+
+				| Name | Type   |
+				|------|--------|
+				| name | string |
+				`),
+			},
+			want: &Doc{
+				Common: Common{Memo: "memo"},
+				Name:   "Find User",
+				CRUD: []CRUD{
+					{Table: "users", R: true, Description: "name is indexed"},
+				},
+				Queries: []Query{
+					{
+						Name: "by name",
+						SQL:  "select * from users where name = /*name*/'bob';",
+						Lang: "sql",
+						Info: "find",
+						Safe: true,
+					},
+				},
+				Params: []map[string]string{
+					{"Name": "name", "Type": "string"},
+				},
+			},
+		},
+		{
+			name: "required column",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					return NewDocJigFromTags[Doc]()
+				},
+				src: TrimIndent(t, `
+				# Find User
+
+				## CRUD Matrix
+
+				| C | R | U | D |
+				|---|---|---|---|
+				|   | X |   |   |
+				`),
+			},
+			wantErr: "required column(Table) are missing (inside '' section)",
+		},
+		{
+			name: "refine by builder methods",
+			args: args{
+				create: func(t *testing.T) *DocJig[Doc] {
+					jig := NewDocJigFromTags[Doc]()
+					jig.Alias("Query").Lang("ja", "クエリー")
+					jig.Root().Child(".", "CRUD Matrix").Table("CRUD").Field("Description", "Desc").Required()
+					return jig
+				},
+				src: TrimIndent(t, `
+				# Find User
+
+				## クエリー: by name
+
+				~~~sql
+				select * from users;
+				~~~
+
+				## CRUD Matrix
+
+				| Table | R |
+				|-------|---|
+				| users | X |
+				`),
+			},
+			wantErr: "required column(Desc) are missing (inside '' section)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := tc.args.create(t)
+			got, err := jig.ParseString(tc.args.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewDocJigFromTags_Invalid(t *testing.T) {
+	type Doc struct {
+		Rows string `mdd:"table"`
+	}
+	assert.PanicsWithError(t, "mdd tag: table field 'Rows' should be slice, but string", func() {
+		NewDocJigFromTags[Doc]()
+	})
+}
+
+func TestNewDocJigFromTags_FieldGroup(t *testing.T) {
+	type CRUD struct {
+		Table string
+		Ops   []string `mdd:"group=C|R|U|D"`
+	}
+
+	type Doc struct {
+		CRUD []CRUD `mdd:"child=CRUD Matrix,table"`
+	}
+
+	jig := NewDocJigFromTags[Doc]()
+	got, err := jig.ParseString(TrimIndent(t, `
+	# Find User
+
+	## CRUD Matrix
+
+	| Table | C | R | U | D |
+	|-------|---|---|---|---|
+	| users |   | X |   |   |
+	`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Doc{CRUD: []CRUD{{Table: "users", Ops: []string{"R"}}}}, got)
+}
@@ -0,0 +1,228 @@
+package mdd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TangleOption is option of [Tangle]
+type TangleOption struct {
+	// LineDirectives emits directives that point to lines of markdown files for compiler errors.
+	// They are "//line doc.md:12" for Go and `#line 12 "doc.md"` for C and C++ files.
+	LineDirectives bool
+	// OutputDir is the directory that files are written into (default is the current directory).
+	// Line directives have document paths relative to the directory of each file (absolute if impossible).
+	OutputDir string
+}
+
+// TangledFile is a source file extracted by [Tangle]
+type TangledFile struct {
+	Name    string // slash separated path from output directory
+	Content string
+}
+
+// tangleSegment is a code fence for tangle
+type tangleSegment struct {
+	doc   string
+	line  int // line number of the first line of body
+	lines []string
+}
+
+var chunkReference = regexp.MustCompile(`^(\s*)<<(.+?)>>\s*$`)
+
+// Tangle extracts source files from code fences of literate documents
+//
+// Code fences that have file attribute are written into the file. Several fences of the same file
+// are concatenated in document order. Documents are read in the order of patterns (like [DocJig.ParseFS]):
+//
+//	```go file=handler.go
+//	package main
+//
+//	<<handler>>
+//	```
+//
+// A line like "<<name>>" is replaced by the code fences that have chunk attribute (noweb style).
+// Indent before "<<" is added to each line of the chunk:
+//
+//	```go chunk=handler
+//	func handle() {}
+//	```
+func Tangle(fsys fs.FS, opt TangleOption, patterns ...string) ([]TangledFile, error) {
+	return tangle(opt, patterns, func(pattern string) ([]string, error) {
+		return fs.Glob(fsys, pattern)
+	}, func(name string) (fs.FileInfo, error) {
+		return fs.Stat(fsys, name)
+	}, func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+// TangleGlob is same as [Tangle] but reads documents in OS file system (like [DocJig.ParseGlob])
+//
+// Patterns can be absolute paths or paths outside the current directory.
+func TangleGlob(opt TangleOption, patterns ...string) ([]TangledFile, error) {
+	return tangle(opt, patterns, filepath.Glob, os.Stat, os.ReadFile)
+}
+
+func tangle(opt TangleOption, patterns []string, glob func(string) ([]string, error), stat func(string) (fs.FileInfo, error), read func(string) ([]byte, error)) ([]TangledFile, error) {
+	var docs []string
+	found := make(map[string]bool)
+	for _, pattern := range patterns {
+		list, err := glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("pattern matches no files: %#q", pattern)
+		}
+		for _, name := range list {
+			if st, err := stat(name); err != nil || st.IsDir() || found[name] {
+				continue
+			}
+			found[name] = true
+			docs = append(docs, name)
+		}
+	}
+
+	var fileNames []string
+	files := make(map[string][]tangleSegment)
+	chunks := make(map[string][]tangleSegment)
+	for _, doc := range docs {
+		src, err := read(doc)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range newFenceLocator(string(src)).fences {
+			fi := parseFenceInfo([]byte(f.info))
+			file, chunk := fi.attrs["file"], fi.attrs["chunk"]
+			if file == "" && chunk == "" {
+				continue
+			}
+			seg := tangleSegment{doc: doc, line: f.line + 1, lines: strings.Split(f.body, "\n")}
+			if f.body == "" {
+				seg.lines = nil
+			}
+			if file != "" {
+				name := path.Clean(filepath.ToSlash(file))
+				if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+					return nil, fmt.Errorf("file '%s' should be relative path in output directory (at %s:%d)", file, doc, f.line)
+				}
+				if _, ok := files[name]; !ok {
+					fileNames = append(fileNames, name)
+				}
+				files[name] = append(files[name], seg)
+			}
+			if chunk != "" {
+				chunks[chunk] = append(chunks[chunk], seg)
+			}
+		}
+	}
+
+	result := make([]TangledFile, 0, len(fileNames))
+	for _, name := range fileNames {
+		t := tangler{chunks: chunks, directive: lineDirective(name, opt)}
+		for _, seg := range files[name] {
+			if err := t.write(seg, "", nil); err != nil {
+				return nil, fmt.Errorf("can't tangle '%s': %w", name, err)
+			}
+		}
+		result = append(result, TangledFile{Name: name, Content: t.b.String()})
+	}
+	return result, nil
+}
+
+// WriteTangledFiles writes files of [Tangle] into dir
+func WriteTangledFiles(dir string, files []TangledFile) error {
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(f.Content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tangler struct {
+	chunks    map[string][]tangleSegment
+	directive func(doc string, line int) string // nil if line directive is not available
+	b         strings.Builder
+}
+
+// write writes lines of the segment with expanding chunk references. stack is the names of expanding chunks.
+func (t *tangler) write(seg tangleSegment, indent string, stack []string) error {
+	if t.directive != nil && len(seg.lines) > 0 {
+		t.b.WriteString(t.directive(seg.doc, seg.line) + "\n")
+	}
+	for i, line := range seg.lines {
+		m := chunkReference.FindStringSubmatch(line)
+		if m == nil {
+			if strings.TrimSpace(line) != "" {
+				t.b.WriteString(indent)
+			}
+			t.b.WriteString(line + "\n")
+			continue
+		}
+		name := strings.TrimSpace(m[2])
+		for _, s := range stack {
+			if s == name {
+				return fmt.Errorf("chunk cycle %s (referenced at %s:%d)", strings.Join(append(stack, name), " -> "), seg.doc, seg.line+i)
+			}
+		}
+		segments, ok := t.chunks[name]
+		if !ok {
+			return fmt.Errorf("undefined chunk '%s' (referenced at %s:%d)", name, seg.doc, seg.line+i)
+		}
+		for _, s := range segments {
+			if err := t.write(s, indent+m[1], append(stack[:len(stack):len(stack)], name)); err != nil {
+				return err
+			}
+		}
+		if t.directive != nil && i+1 < len(seg.lines) {
+			t.b.WriteString(t.directive(seg.doc, seg.line+i+1) + "\n")
+		}
+	}
+	return nil
+}
+
+func lineDirective(name string, opt TangleOption) func(doc string, line int) string {
+	if !opt.LineDirectives {
+		return nil
+	}
+	dir := filepath.Dir(filepath.Join(opt.OutputDir, filepath.FromSlash(name)))
+	switch strings.ToLower(path.Ext(name)) {
+	case ".go":
+		return func(doc string, line int) string {
+			return fmt.Sprintf("//line %s:%d", docPathFrom(dir, doc), line)
+		}
+	case ".c", ".h", ".cc", ".cpp", ".cxx", ".hpp":
+		return func(doc string, line int) string {
+			return fmt.Sprintf("#line %d %q", line, docPathFrom(dir, doc))
+		}
+	}
+	return nil
+}
+
+// docPathFrom returns path of doc relative to dir (absolute path if impossible).
+// Compilers resolve relative paths of line directives from the directory of the source file.
+func docPathFrom(dir, doc string) string {
+	if filepath.IsAbs(doc) {
+		return doc
+	}
+	if !filepath.IsAbs(dir) {
+		if rel, err := filepath.Rel(dir, doc); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	if abs, err := filepath.Abs(doc); err == nil {
+		return abs
+	}
+	return doc
+}
@@ -0,0 +1,116 @@
+package mdd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTangle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/1_main.md": {Data: []byte(TrimIndent(t, `
+		# Main
+
+		~~~go file=main.go
+		package main
+
+		func main() {
+			<<parse args>>
+			run()
+		}
+		~~~
+		`))},
+		"docs/2_run.md": {Data: []byte(TrimIndent(t, `
+		# Run
+
+		~~~go chunk="parse args"
+		flag.Parse()
+		~~~
+
+		~~~go file=main.go
+		func run() {}
+		~~~
+
+		~~~go chunk="parse args"
+		if flag.NArg() == 0 {
+			return
+		}
+		~~~
+		`))},
+	}
+
+	t.Run("tangle", func(t *testing.T) {
+		got, err := Tangle(fsys, TangleOption{}, "docs/*.md")
+		assert.NoError(t, err)
+		assert.Equal(t, []TangledFile{{
+			Name: "main.go",
+			Content: "package main\n\nfunc main() {\n" +
+				"\tflag.Parse()\n\tif flag.NArg() == 0 {\n\t\treturn\n\t}\n" +
+				"\trun()\n}\nfunc run() {}\n",
+		}}, got)
+	})
+
+	t.Run("line directives", func(t *testing.T) {
+		got, err := Tangle(fsys, TangleOption{LineDirectives: true}, "docs/*.md")
+		assert.NoError(t, err)
+		assert.Equal(t, "//line docs/1_main.md:4\npackage main\n\nfunc main() {\n"+
+			"//line docs/2_run.md:4\n\tflag.Parse()\n"+
+			"//line docs/2_run.md:12\n\tif flag.NArg() == 0 {\n\t\treturn\n\t}\n"+
+			"//line docs/1_main.md:8\n\trun()\n}\n"+
+			"//line docs/2_run.md:8\nfunc run() {}\n", got[0].Content)
+	})
+
+	t.Run("line directives in output dir", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"docs/hello.md": {Data: []byte(TrimIndent(t, `
+			# Hello
+
+			~~~go file=cmd/hello/main.go
+			package main
+			~~~
+
+			~~~c file=hello.c
+			int main() {}
+			~~~
+			`))},
+		}
+		got, err := Tangle(fsys, TangleOption{LineDirectives: true, OutputDir: "gen"}, "docs/*.md")
+		assert.NoError(t, err)
+		assert.Equal(t, []TangledFile{
+			{Name: "cmd/hello/main.go", Content: "//line ../../../docs/hello.md:4\npackage main\n"},
+			{Name: "hello.c", Content: "#line 8 \"../docs/hello.md\"\nint main() {}\n"},
+		}, got)
+	})
+
+	t.Run("undefined chunk", func(t *testing.T) {
+		_, err := Tangle(fsys, TangleOption{}, "docs/1_main.md")
+		assert.EqualError(t, err, "can't tangle 'main.go': undefined chunk 'parse args' (referenced at docs/1_main.md:7)")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"loop.md": {Data: []byte("```go file=a.go\n<<a>>\n```\n\n```go chunk=a\n<<b>>\n```\n\n```go chunk=b\n  <<a>>\n```\n")},
+		}
+		_, err := Tangle(fsys, TangleOption{}, "loop.md")
+		assert.EqualError(t, err, "can't tangle 'a.go': chunk cycle a -> b -> a (referenced at loop.md:10)")
+	})
+
+	t.Run("invalid file name", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"doc.md": {Data: []byte("```go file=../a.go\n```\n")},
+		}
+		_, err := Tangle(fsys, TangleOption{}, "doc.md")
+		assert.EqualError(t, err, "file '../a.go' should be relative path in output directory (at doc.md:1)")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, WriteTangledFiles(dir, []TangledFile{{Name: "cmd/main.go", Content: "package main\n"}}))
+		c, err := os.ReadFile(filepath.Join(dir, "cmd", "main.go"))
+		assert.NoError(t, err)
+		assert.Equal(t, "package main\n", string(c))
+	})
+}
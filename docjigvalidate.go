@@ -0,0 +1,258 @@
+package mdd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FenceError is an error about content of code fence
+//
+// Line is 1-based line number in the code fence body, or in the markdown file when it is
+// returned from parse methods (0 if unknown). Column is 1-based (0 if unknown). File is set
+// when the content is read by include attribute and then Line is the line in the file.
+type FenceError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FenceError) Error() string {
+	var of string
+	if e.File != "" {
+		of = fmt.Sprintf(" of '%s'", e.File)
+	}
+	switch {
+	case e.Line == 0 && e.File != "":
+		return fmt.Sprintf("'%s': %v", e.File, e.Err)
+	case e.Line == 0:
+		return e.Err.Error()
+	case e.Column == 0:
+		return fmt.Sprintf("line %d%s: %v", e.Line, of, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d%s: %v", e.Line, e.Column, of, e.Err)
+}
+
+func (e *FenceError) Unwrap() error {
+	return e.Err
+}
+
+// locate converts line number in content of code fence into line number in markdown file
+// (or included file)
+func (e *FenceError) locate(fi fenceInfo) *FenceError {
+	line := e.Line
+	if line != 0 {
+		line += fi.offset
+	}
+	switch {
+	case fi.include != "":
+		if line == 0 || line > len(fi.lines) {
+			return &FenceError{File: fi.include, Err: e.Err}
+		}
+		return &FenceError{File: fi.include, Line: fi.lines[line-1], Column: e.Column, Err: e.Err}
+	case line == 0:
+		return e
+	}
+	return &FenceError{Line: fi.line + line, Column: e.Column, Err: e.Err}
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+): `)
+
+// newFenceError extracts position from errors of encoding/csv, encoding/json, yaml.v3 and go/scanner
+func newFenceError(err error, code string) *FenceError {
+	offsetLine := func(offset int64) int {
+		if offset > int64(len(code)) {
+			offset = int64(len(code))
+		}
+		return strings.Count(code[:offset], "\n") + 1
+	}
+	var fenceErr *FenceError
+	var csvErr *csv.ParseError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var goErrs scanner.ErrorList
+	switch {
+	case errors.As(err, &fenceErr):
+		return fenceErr
+	case errors.As(err, &csvErr):
+		return &FenceError{Line: csvErr.Line, Column: csvErr.Column, Err: csvErr.Err}
+	case errors.As(err, &syntaxErr):
+		return &FenceError{Line: offsetLine(syntaxErr.Offset), Err: err}
+	case errors.As(err, &typeErr):
+		return &FenceError{Line: offsetLine(typeErr.Offset), Err: err}
+	case errors.As(err, &goErrs) && len(goErrs) > 0:
+		return &FenceError{Line: goErrs[0].Pos.Line, Column: goErrs[0].Pos.Column, Err: errors.New(goErrs[0].Msg)}
+	case strings.HasPrefix(err.Error(), "yaml:"):
+		if m := yamlLinePattern.FindStringSubmatchIndex(err.Error()); m != nil {
+			msg := err.Error()
+			line, _ := strconv.Atoi(msg[m[2]:m[3]])
+			return &FenceError{Line: line, Err: errors.New(msg[:m[0]] + msg[m[1]:])}
+		}
+	}
+	return &FenceError{Err: err}
+}
+
+// Validate adds validator of code fence content. Validators are called before the content is stored.
+//
+// Validators can return [FenceError] to point the line in the fence body. Errors of
+// encoding/json, encoding/csv, yaml.v3 and go/scanner are converted automatically.
+// Built-in validators are [ValidateJSON], [ValidateYAML], [ValidateGo] and [ValidateSQL]:
+//
+//	root.CodeFence("Config", "json").Validate(mdd.ValidateJSON)
+func (cf *CodeFence[T]) Validate(validator func(code, lang, info string) error) *CodeFence[T] {
+	cf.validators = append(cf.validators, validator)
+	return cf
+}
+
+// validate calls validators. Line numbers of errors are converted into line numbers of markdown file.
+func (cf *CodeFence[T]) validate(code string, fi fenceInfo, label string) error {
+	for _, v := range cf.validators {
+		if err := v(code, fi.lang, fi.info); err != nil {
+			return fmt.Errorf("invalid %s code fence%s (inside '%s' section): %w", fi.lang, atLine(fi.line), label, newFenceError(err, code).locate(fi))
+		}
+	}
+	return nil
+}
+
+// ValidateJSON is a validator for [CodeFence.Validate] that checks JSON syntax
+func ValidateJSON(code, lang, info string) error {
+	var v any
+	return json.Unmarshal([]byte(code), &v)
+}
+
+// ValidateYAML is a validator for [CodeFence.Validate] that checks YAML syntax
+func ValidateYAML(code, lang, info string) error {
+	var v any
+	return yaml.Unmarshal([]byte(code), &v)
+}
+
+// ValidateGo is a validator for [CodeFence.Validate] that checks Go syntax by go/parser
+//
+// Snippets without package clause are accepted if they are valid as declarations or statements.
+func ValidateGo(code, lang, info string) error {
+	type attempt struct {
+		prefix, suffix string
+		lines          int // lines added by prefix
+	}
+	attempts := []attempt{
+		{},
+		{prefix: "package p;", lines: 0},
+		{prefix: "package p; func _() {\n", suffix: "\n}", lines: 1},
+	}
+	var best *FenceError
+	for _, a := range attempts {
+		_, err := parser.ParseFile(token.NewFileSet(), "", a.prefix+code+a.suffix, parser.AllErrors)
+		if err == nil {
+			return nil
+		}
+		fe := newFenceError(err, code)
+		if fe.Line != 0 {
+			fe.Line -= a.lines
+		}
+		// the attempt that parses more lines is the most likely form
+		if best == nil || fe.Line > best.Line || (fe.Line == best.Line && fe.Column > best.Column) {
+			best = fe
+		}
+	}
+	return best
+}
+
+// ValidateSQL is a validator for [CodeFence.Validate] that checks SQL can be split into statements
+//
+// It reports unterminated quotes and comments. See [SplitSQL].
+func ValidateSQL(code, lang, info string) error {
+	_, err := SplitSQL(code)
+	return err
+}
+
+// SplitSQL splits SQL script into statements by semicolons
+//
+// Semicolons in quotes (', ", `), comments (--, /* */) and dollar-quoted strings ($$, $tag$)
+// are ignored. Backslash escapes a character in ' and " quotes (MySQL style). Statements are trimmed and empty statements are removed.
+// Unterminated quotes and comments are reported as [FenceError].
+func SplitSQL(code string) ([]string, error) {
+	var result []string
+	start := 0
+	line, column := 1, 1
+	// position of the opening of current quote or comment
+	openLine, openColumn := 0, 0
+	var closing string // closing token of current quote or comment
+	flush := func(end int) {
+		if s := strings.TrimSpace(code[start:end]); s != "" {
+			result = append(result, s)
+		}
+	}
+	for i := 0; i < len(code); {
+		c := code[i]
+		_, advance := utf8.DecodeRuneInString(code[i:])
+		switch {
+		case closing != "":
+			if c == '\\' && (closing == "'" || closing == "\"") && i+1 < len(code) {
+				_, size := utf8.DecodeRuneInString(code[i+1:])
+				advance += size
+			} else if strings.HasPrefix(code[i:], closing) {
+				advance = len(closing)
+				closing = ""
+			}
+		case c == '\'' || c == '"' || c == '`':
+			closing = string(c)
+		case strings.HasPrefix(code[i:], "--"):
+			closing = "\n"
+		case strings.HasPrefix(code[i:], "/*"):
+			closing = "*/"
+			advance = 2
+		case c == '$':
+			if m := dollarQuote.FindString(code[i:]); m != "" {
+				closing = m
+				advance = len(m)
+			}
+		case c == ';':
+			flush(i)
+			start = i + 1
+		}
+		if closing != "" && openLine == 0 {
+			openLine, openColumn = line, column
+		} else if closing == "" {
+			openLine = 0
+		}
+		for _, ch := range code[i : i+advance] {
+			if ch == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+		}
+		i += advance
+	}
+	if closing != "" && closing != "\n" {
+		return nil, &FenceError{Line: openLine, Column: openColumn, Err: fmt.Errorf("unterminated %s", sqlTokenName(closing))}
+	}
+	flush(len(code))
+	return result, nil
+}
+
+var dollarQuote = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+func sqlTokenName(closing string) string {
+	switch closing {
+	case "*/":
+		return "comment"
+	case "'":
+		return "string literal"
+	case "\"", "`":
+		return "quoted identifier"
+	}
+	return "dollar-quoted string"
+}
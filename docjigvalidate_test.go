@@ -0,0 +1,124 @@
+package mdd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeFence_Validate(t *testing.T) {
+	type Doc struct {
+		Code string
+	}
+
+	tests := []struct {
+		name      string
+		validator func(code, lang, info string) error
+		src       string
+		wantErr   string
+	}{
+		{
+			name:      "json: ok",
+			validator: ValidateJSON,
+			src:       "# Doc\n\n```json\n{\"a\": 1}\n```\n",
+		},
+		{
+			name:      "json: error",
+			validator: ValidateJSON,
+			src:       "# Doc\n\n```json\n{\n  \"a\": 1,\n}\n```\n",
+			wantErr:   "invalid json code fence at line 3 (inside 'Doc' section): line 6: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			name:      "yaml: error",
+			validator: ValidateYAML,
+			src:       "# Doc\n\n```yaml\na: 1\nb: [\n```\n",
+			wantErr:   "invalid yaml code fence at line 3 (inside 'Doc' section): line 5: yaml: did not find expected node content",
+		},
+		{
+			name:      "go: file",
+			validator: ValidateGo,
+			src:       "# Doc\n\n```go\npackage main\n\nfunc main() {}\n```\n",
+		},
+		{
+			name:      "go: declarations",
+			validator: ValidateGo,
+			src:       "# Doc\n\n```go\nfunc add(a, b int) int {\n\treturn a + b\n}\n```\n",
+		},
+		{
+			name:      "go: statements",
+			validator: ValidateGo,
+			src:       "# Doc\n\n```go\nx := 1\nfmt.Println(x)\n```\n",
+		},
+		{
+			name:      "go: error",
+			validator: ValidateGo,
+			src:       "# Doc\n\n```go\nx := 1\nfmt.Println(x\ny := 2\n```\n",
+			wantErr:   "invalid go code fence at line 3 (inside 'Doc' section): line 5, column 14: missing ',' before newline in argument list",
+		},
+		{
+			name:      "sql: ok",
+			validator: ValidateSQL,
+			src:       "# Doc\n\n```sql\nselect ';' from a; -- comment;\nselect 2;\n```\n",
+		},
+		{
+			name:      "sql: unterminated string",
+			validator: ValidateSQL,
+			src:       "# Doc\n\n```sql\nselect 1;\nselect 'abc from users;\n```\n",
+			wantErr:   "invalid sql code fence at line 3 (inside 'Doc' section): line 5, column 8: unterminated string literal",
+		},
+		{
+			name:      "sql: leading blank lines",
+			validator: ValidateSQL,
+			src:       "# Doc\n\n```sql\n\n\nselect 1;\nselect 'abc from users;\n```\n",
+			wantErr:   "invalid sql code fence at line 3 (inside 'Doc' section): line 7, column 8: unterminated string literal",
+		},
+		{
+			name: "custom",
+			validator: func(code, lang, info string) error {
+				return &FenceError{Line: 2, Err: errors.New("TODO is left")}
+			},
+			src:     "# Doc\n\n```txt\nhello\nTODO\n```\n",
+			wantErr: "invalid txt code fence at line 3 (inside 'Doc' section): line 5: TODO is left",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jig := NewDocJig[Doc]()
+			jig.Root().CodeFence("Code").Validate(tc.validator)
+			got, err := jig.ParseString(tc.src)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				var fe *FenceError
+				assert.True(t, errors.As(err, &fe))
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, got.Code)
+			}
+		})
+	}
+}
+
+func TestSplitSQL(t *testing.T) {
+	got, err := SplitSQL(`
+create function f() returns int as $body$ select 1; $body$ language sql;
+/* comment; */ insert into "a;b" values ('x;y');
+select 3`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"create function f() returns int as $body$ select 1; $body$ language sql",
+		`/* comment; */ insert into "a;b" values ('x;y')`,
+		"select 3",
+	}, got)
+
+	_, err = SplitSQL("select 1; /* open")
+	assert.EqualError(t, err, "line 1, column 11: unterminated comment")
+
+	got, err = SplitSQL(`select 'a\'b;'; select "c\\"; select 'it''s'`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`select 'a\'b;'`, `select "c\\"`, `select 'it''s'`}, got)
+
+	// columns are counted in characters
+	_, err = SplitSQL("select 'ユーザー'; select 'open")
+	assert.EqualError(t, err, "line 1, column 23: unterminated string literal")
+}
@@ -3,15 +3,16 @@ module github.com/shibukawa/mdd-go
 go 1.19
 
 require (
+	github.com/alecthomas/chroma/v2 v2.2.0
 	github.com/future-architect/tagscanner v1.0.1
 	github.com/russross/blackfriday/v2 v2.1.0
 	github.com/shibukawa/formatdata-go v0.1.3
 	github.com/stretchr/testify v1.8.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/acarl005/stripansi v0.0.0-20180116102854-5a71ef0e047d // indirect
-	github.com/alecthomas/chroma/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dlclark/regexp2 v1.4.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
@@ -22,5 +23,4 @@ require (
 	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
 	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1 // indirect
 	golang.org/x/text v0.3.7 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -7,6 +7,8 @@ import (
 )
 
 // plainTextRenderer removes inline markups and return plain text
+//
+// Line break tag (<br>) becomes newline to keep list-valued table cells splittable.
 func plainTextRenderer(node *blackfriday.Node) string {
 	var builder strings.Builder
 	node.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
@@ -17,8 +19,17 @@ func plainTextRenderer(node *blackfriday.Node) string {
 			builder.Write(node.Literal)
 		case blackfriday.Code:
 			builder.Write(node.Literal)
+		case blackfriday.HTMLSpan:
+			if isLineBreakTag(node.Literal) {
+				builder.WriteByte('\n')
+			}
 		}
 		return blackfriday.GoToNext
 	})
 	return builder.String()
 }
+
+func isLineBreakTag(tag []byte) bool {
+	t := strings.ToLower(strings.ReplaceAll(string(tag), " ", ""))
+	return t == "<br>" || t == "<br/>"
+}